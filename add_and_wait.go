@@ -0,0 +1,62 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDuplicateTorrent is returned by AddAndWait, with the id of the existing
+// torrent, when the daemon already had the torrent of the magnet link.
+var ErrDuplicateTorrent = errors.New("torrent already added")
+
+// AddAndWait adds a magnet link, waits for its metadata to download, marks
+// every file unwanted and stops it, calls apply with the complete torrent and
+// then starts it again. apply selects the files to download, with SelectFiles
+// or FilesWantedOption, or sets labels; if apply is nil every file is wanted
+// again. The torrent stays stopped if apply returns an error. Use a context
+// with a deadline to limit the wait, and ProgressOption to report progress.
+//
+// A torrent the daemon already had is left untouched, its files and status
+// are those chosen before, and ErrDuplicateTorrent is returned with its id.
+//
+// The torrent isn't added paused, as a paused magnet link never fetches its
+// metadata. Files are unwanted as soon as the metadata is seen, and polling
+// doesn't back off by default, to keep the time the torrent downloads every
+// file short.
+func (t *Client) AddAndWait(ctx context.Context, link string, addOpts []AddMagnetLinkOption, apply func(context.Context, *Torrent) error, opts ...WaitOption) (int, error) {
+	id, duplicate, err := t.addMagnetLink(ctx, link, addOpts)
+	if err != nil {
+		return 0, err
+	}
+	if duplicate {
+		return id, ErrDuplicateTorrent
+	}
+	opts = append([]WaitOption{MaxPollIntervalOption(defaultPollInterval)}, opts...)
+	if _, err := t.WaitFor(ctx, ID(id), MetadataReceived, opts...); err != nil {
+		return id, err
+	}
+	// An empty list applies to every file
+	if err := t.SetTorrents(ctx, IDs(id), FilesUnwantedOption()); err != nil {
+		return id, err
+	}
+	if err := t.StopTorrents(ctx, ID(id)); err != nil {
+		return id, err
+	}
+	torrents, err := t.GetTorrents(ctx, ID(id))
+	if err != nil {
+		return id, err
+	}
+	if len(torrents) == 0 {
+		return id, fmt.Errorf("torrent %d was removed while waiting for metadata", id)
+	}
+	if apply == nil {
+		err = t.SetTorrents(ctx, IDs(id), FilesWantedOption())
+	} else {
+		err = apply(ctx, &torrents[0])
+	}
+	if err != nil {
+		return id, err
+	}
+	return id, t.StartTorrents(ctx, ID(id))
+}
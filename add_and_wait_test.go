@@ -0,0 +1,133 @@
+package transmission_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/transmissiontest"
+)
+
+const waitMagnet = "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567&dn=season"
+
+func registerSeason(server *transmissiontest.Server) {
+	var metadata transmission.Torrent
+	metadata.HashString = "0123456789abcdef0123456789abcdef01234567"
+	metadata.Name = "season"
+	metadata.Files = []transmission.TorrentFile{
+		{Name: "season/episode 1.mkv", Length: 1 << 30},
+		{Name: "season/episode 2.mkv", Length: 1 << 30},
+	}
+	server.RegisterMetadata(metadata)
+}
+
+// advanceOnPoll moves the simulation forward a second on the polls before the
+// metadata arrives, which it does after the first one.
+func advanceOnPoll(server *transmissiontest.Server) transmission.WaitOption {
+	return transmission.ProgressOption(func(torrent *transmission.Torrent) {
+		if torrent.MetadataPercentComplete < 1 {
+			server.Advance(time.Second)
+		}
+	})
+}
+
+func TestAddAndWait(t *testing.T) {
+	client, server := newTestClient(t)
+	registerSeason(server)
+	var applied *transmission.Torrent
+	apply := func(ctx context.Context, torrent *transmission.Torrent) error {
+		applied = torrent
+		for _, stats := range torrent.FileStats {
+			if stats.Wanted {
+				t.Error("apply was called with wanted files")
+			}
+		}
+		if torrent.Status != transmission.StatusStopped {
+			t.Errorf("apply was called with status %d, want stopped", torrent.Status)
+		}
+		return client.SetTorrents(ctx, transmission.IDs(torrent.ID), transmission.FilesWantedOption(0))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	id, err := client.AddAndWait(ctx, waitMagnet, nil, apply, transmission.PollIntervalOption(time.Millisecond), advanceOnPoll(server))
+	if err != nil {
+		t.Fatalf("AddAndWait: %v", err)
+	}
+	if applied == nil || len(applied.Files) != 2 {
+		t.Fatalf("apply got %+v, want the torrent with its files", applied)
+	}
+	torrent, _ := server.Torrent(id)
+	if torrent.Status != transmission.StatusDownload {
+		t.Errorf("status %d after AddAndWait, want downloading", torrent.Status)
+	}
+	if !torrent.FileStats[0].Wanted || torrent.FileStats[1].Wanted {
+		t.Errorf("got file stats %+v, want only the first file", torrent.FileStats)
+	}
+	// Unwanting the files stops the data first, stopping comes after
+	var calls []string
+	for _, request := range server.Requests() {
+		if request.Method == "torrent-set" || request.Method == "torrent-stop" {
+			calls = append(calls, request.Method+" "+string(request.Arguments))
+		}
+	}
+	if len(calls) < 2 || !strings.Contains(calls[0], `"files-unwanted":[]`) || !strings.HasPrefix(calls[1], "torrent-stop") {
+		t.Errorf("got calls %q, want every file unwanted and then a stop", calls)
+	}
+}
+
+func TestAddAndWaitWithoutApply(t *testing.T) {
+	client, server := newTestClient(t)
+	registerSeason(server)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	id, err := client.AddAndWait(ctx, waitMagnet, nil, nil, transmission.PollIntervalOption(time.Millisecond), advanceOnPoll(server))
+	if err != nil {
+		t.Fatalf("AddAndWait: %v", err)
+	}
+	torrent, _ := server.Torrent(id)
+	for i, stats := range torrent.FileStats {
+		if !stats.Wanted {
+			t.Errorf("file %d unwanted without apply", i)
+		}
+	}
+}
+
+func TestAddAndWaitDuplicate(t *testing.T) {
+	client, server := newTestClient(t)
+	registerSeason(server)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	first, err := client.AddAndWait(ctx, waitMagnet, nil, func(ctx context.Context, torrent *transmission.Torrent) error {
+		return client.SetTorrents(ctx, transmission.IDs(torrent.ID), transmission.FilesWantedOption(1))
+	}, transmission.PollIntervalOption(time.Millisecond), advanceOnPoll(server))
+	if err != nil {
+		t.Fatalf("AddAndWait: %v", err)
+	}
+	if err := client.StopTorrents(ctx, transmission.ID(first)); err != nil {
+		t.Fatalf("StopTorrents: %v", err)
+	}
+	requests := len(server.Requests())
+
+	apply := func(context.Context, *transmission.Torrent) error {
+		t.Error("apply was called for a duplicate")
+		return nil
+	}
+	id, err := client.AddAndWait(ctx, waitMagnet, nil, apply, transmission.PollIntervalOption(time.Millisecond))
+	if !errors.Is(err, transmission.ErrDuplicateTorrent) || id != first {
+		t.Fatalf("AddAndWait returned %d, %v, want %d, ErrDuplicateTorrent", id, err, first)
+	}
+	// Only the torrent-add was sent, the selection and status are kept
+	if calls := server.Requests()[requests:]; len(calls) != 1 || calls[0].Method != "torrent-add" {
+		t.Errorf("got %d more calls, want only torrent-add", len(calls))
+	}
+	torrent, _ := server.Torrent(id)
+	if torrent.Status != transmission.StatusStopped {
+		t.Errorf("status %d after a duplicate, want it still stopped", torrent.Status)
+	}
+	if torrent.FileStats[0].Wanted || !torrent.FileStats[1].Wanted {
+		t.Errorf("got file stats %+v, want only the second file still", torrent.FileStats)
+	}
+}
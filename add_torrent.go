@@ -2,20 +2,30 @@ package transmission
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"path"
+
+	"github.com/bobcob7/transmission-rpc/bencode"
 )
 
 type addTransmissionResponse struct {
 	Result    string                      `json:"result"`
 	Arguments addTransmissionResponseArgs `json:"arguments"`
-	Tag       string                      `json:"tag"`
+	Tag       int64                       `json:"tag"`
 }
 
 type addTransmissionRequestArgs struct {
-	Paused      string `json:"paused"`
-	DownloadDir string `json:"download-dir"`
-	Filename    string `json:"filename"`
+	Paused      bool     `json:"paused,omitempty"`
+	DownloadDir string   `json:"download-dir"`
+	Filename    string   `json:"filename,omitempty"`
+	Metainfo    string   `json:"metainfo,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	// metainfo is the .torrent file, base64 encoded into Metainfo once the
+	// options have been applied
+	metainfo []byte
+	trackers []string
 }
 
 type addTransmissionResponseArgs struct {
@@ -46,23 +56,148 @@ func DownloadSubDirOption(subDir string) AddMagnetLinkOption {
 	}
 }
 
+// PausedOption adds the torrent without starting it.
+func PausedOption() AddMagnetLinkOption {
+	return func(req *addTransmissionRequestArgs) {
+		req.Paused = true
+	}
+}
+
+// AddLabelsOption labels the torrent as it is added. It needs Transmission 4.0.
+func AddLabelsOption(labels ...string) AddMagnetLinkOption {
+	return func(req *addTransmissionRequestArgs) {
+		req.Labels = append(req.Labels, labels...)
+	}
+}
+
+// AddTrackersOption adds trackers the torrent doesn't already have, as the
+// last tiers. The link or torrent file is changed before it is sent, which
+// doesn't change the info hash.
+func AddTrackersOption(trackers ...string) AddMagnetLinkOption {
+	return func(req *addTransmissionRequestArgs) {
+		req.trackers = append(req.trackers, trackers...)
+	}
+}
+
+// AddMagnetLink adds a torrent from a magnet link. Malformed links fail
+// without a call to the daemon, see ParseMagnet.
 func (t *Client) AddMagnetLink(ctx context.Context, link string, opts ...AddMagnetLinkOption) (int, error) {
-	var response addTransmissionResponse
-	req := addTransmissionRequestArgs{
-		Filename:    link,
-		DownloadDir: t.DownloadDir,
+	id, _, err := t.addMagnetLink(ctx, link, opts)
+	return id, err
+}
+
+// addMagnetLink adds a magnet link, and reports whether the daemon already had
+// the torrent.
+func (t *Client) addMagnetLink(ctx context.Context, link string, opts []AddMagnetLinkOption) (int, bool, error) {
+	if _, err := ParseMagnet(link); err != nil {
+		return 0, false, fmt.Errorf("failed to add magnet link: %w", err)
+	}
+	id, duplicate, err := t.addTorrent(ctx, addTransmissionRequestArgs{Filename: link}, opts)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to add magnet link: %w", err)
 	}
+	return id, duplicate, nil
+}
+
+// AddTorrentFile adds a torrent from the contents of a .torrent file, and
+// takes the same options as AddMagnetLink.
+func (t *Client) AddTorrentFile(ctx context.Context, metainfo []byte, opts ...AddMagnetLinkOption) (int, error) {
+	id, _, err := t.addTorrent(ctx, addTransmissionRequestArgs{metainfo: metainfo}, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add torrent file: %w", err)
+	}
+	return id, nil
+}
+
+// addTorrent adds a torrent, and reports whether it is a duplicate of one the
+// daemon already had, whose id is returned instead.
+func (t *Client) addTorrent(ctx context.Context, req addTransmissionRequestArgs, opts []AddMagnetLinkOption) (int, bool, error) {
+	var response addTransmissionResponse
+	req.DownloadDir = t.DownloadDir
 	for _, opt := range opts {
 		opt(&req)
 	}
+	if len(req.trackers) > 0 && req.Filename != "" {
+		magnet, err := ParseMagnet(req.Filename)
+		if err != nil {
+			return 0, false, err
+		}
+		magnet.AddTrackers(req.trackers...)
+		req.Filename = magnet.String()
+	}
+	if req.metainfo != nil {
+		metainfo := req.metainfo
+		if len(req.trackers) > 0 {
+			var err error
+			if metainfo, err = addMetainfoTrackers(metainfo, req.trackers); err != nil {
+				return 0, false, err
+			}
+		}
+		req.Metainfo = base64.StdEncoding.EncodeToString(metainfo)
+	}
 	if err := t.callRPC(ctx, "torrent-add", &req, &response); err != nil {
-		return 0, err
+		// Daemons before 3.00 report duplicates as a failed result
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Message == "duplicate torrent" {
+			return response.Arguments.TorrentDuplicate.ID, true, nil
+		}
+		return 0, false, err
+	}
+	if response.Arguments.TorrentAdded.ID == 0 {
+		return response.Arguments.TorrentDuplicate.ID, true, nil
+	}
+	return response.Arguments.TorrentAdded.ID, false, nil
+}
+
+// addMetainfoTrackers adds the trackers a .torrent file doesn't already have,
+// each as a tier of its own. The info dictionary is copied as is.
+func addMetainfoTrackers(metainfo []byte, trackers []string) ([]byte, error) {
+	var torrent map[string]bencode.RawMessage
+	if err := bencode.Unmarshal(metainfo, &torrent); err != nil {
+		return nil, fmt.Errorf("invalid torrent file: %w", err)
+	}
+	var tiers [][]string
+	if raw, ok := torrent["announce-list"]; ok {
+		if err := bencode.Unmarshal(raw, &tiers); err != nil {
+			return nil, fmt.Errorf("invalid announce-list: %w", err)
+		}
+	}
+	var announce string
+	if raw, ok := torrent["announce"]; ok {
+		if err := bencode.Unmarshal(raw, &announce); err != nil {
+			return nil, fmt.Errorf("invalid announce: %w", err)
+		}
+	}
+	// The announce-list replaces the announce URL, which has to be kept in it
+	if len(tiers) == 0 && announce != "" {
+		tiers = [][]string{{announce}}
+	}
+	have := make(map[string]bool)
+	nonEmpty := tiers[:0]
+	for _, tier := range tiers {
+		for _, tracker := range tier {
+			have[tracker] = true
+		}
+		if len(tier) > 0 {
+			nonEmpty = append(nonEmpty, tier)
+		}
+	}
+	tiers = nonEmpty
+	for _, tracker := range trackers {
+		if !have[tracker] {
+			have[tracker] = true
+			tiers = append(tiers, []string{tracker})
+		}
 	}
-	if response.Result == "success" {
-		return response.Arguments.TorrentAdded.ID, nil
+	raw, err := bencode.Marshal(tiers)
+	if err != nil {
+		return nil, err
 	}
-	if response.Result == "duplicate torrent" {
-		return response.Arguments.TorrentDuplicate.ID, nil
+	torrent["announce-list"] = raw
+	if announce == "" {
+		if torrent["announce"], err = bencode.Marshal(tiers[0][0]); err != nil {
+			return nil, err
+		}
 	}
-	return 0, fmt.Errorf("failed to add magnet link: %s", response.Result)
+	return bencode.Marshal(torrent)
 }
@@ -0,0 +1,66 @@
+package transmission
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+//go:generate moq -out transmissionmock/api.go -pkg transmissionmock . API
+
+// TorrentReader reads torrents from a server.
+type TorrentReader interface {
+	GetTorrents(ctx context.Context, ids ...TorrentID) ([]Torrent, error)
+	IterateTorrents(ctx context.Context, fn func(Torrent) error, opts ...TorrentsOption) error
+	GetRecentlyActiveTorrents(ctx context.Context, opts ...TorrentsOption) ([]Torrent, []int, error)
+	WaitFor(ctx context.Context, id TorrentID, cond func(*Torrent) bool, opts ...WaitOption) (*Torrent, error)
+}
+
+// TorrentWriter adds, changes and removes torrents on a server.
+type TorrentWriter interface {
+	AddMagnetLink(ctx context.Context, link string, opts ...AddMagnetLinkOption) (int, error)
+	AddTorrentFile(ctx context.Context, metainfo []byte, opts ...AddMagnetLinkOption) (int, error)
+	AddAndWait(ctx context.Context, link string, addOpts []AddMagnetLinkOption, apply func(context.Context, *Torrent) error, opts ...WaitOption) (int, error)
+	StartTorrents(ctx context.Context, ids ...TorrentID) error
+	StopTorrents(ctx context.Context, ids ...TorrentID) error
+	VerifyTorrents(ctx context.Context, ids ...TorrentID) error
+	ReannounceTorrents(ctx context.Context, ids ...TorrentID) error
+	RemoveTorrents(ctx context.Context, deleteLocalData bool, ids ...TorrentID) error
+	SetTorrentLocation(ctx context.Context, location string, move bool, ids ...TorrentID) error
+	SetTorrents(ctx context.Context, ids []TorrentID, opts ...SetTorrentsOption) error
+	SelectFiles(ctx context.Context, id TorrentID, rules ...FileRule) (*FileSelection, error)
+	AssignBandwidthGroup(ctx context.Context, group string, ids ...TorrentID) error
+	QueueMoveTop(ctx context.Context, ids ...TorrentID) error
+	QueueMoveUp(ctx context.Context, ids ...TorrentID) error
+	QueueMoveDown(ctx context.Context, ids ...TorrentID) error
+	QueueMoveBottom(ctx context.Context, ids ...TorrentID) error
+}
+
+// SessionAPI reads and changes the settings and state of a server.
+type SessionAPI interface {
+	Protocol() Protocol
+	GetSession(ctx context.Context) (map[string]interface{}, error)
+	SetSession(ctx context.Context, settings map[string]interface{}) error
+	GetSessionStats(ctx context.Context) (*Session, error)
+	FreeSpace(ctx context.Context, path string) (freeBytes, totalBytes int64, err error)
+	PortTest(ctx context.Context, ipProtocol IPProtocol) (bool, error)
+	CloseSession(ctx context.Context) error
+	WaitForShutdown(ctx context.Context, interval time.Duration) error
+	GetBlocklistSettings(ctx context.Context) (*BlocklistSettings, error)
+	SetBlocklist(ctx context.Context, enabled bool, url string) error
+	UpdateBlocklist(ctx context.Context) (int, error)
+	PushBlocklist(ctx context.Context, blocklist io.Reader, opts ...PushBlocklistOption) (int, error)
+	GetBandwidthGroups(ctx context.Context, names ...string) ([]BandwidthGroup, error)
+	SetBandwidthGroup(ctx context.Context, group BandwidthGroup) error
+}
+
+// API is everything a Client can do. Wrappers adding caching or rate limiting,
+// and mocks from the transmissionmock package, can stand in for a Client
+// wherever an API is expected.
+type API interface {
+	TorrentReader
+	TorrentWriter
+	SessionAPI
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,345 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+// AuditEntry records an action taken, or that would have been taken in dry
+// run mode, on one torrent.
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Rule    string    `json:"rule"`
+	Action  string    `json:"action"`
+	ID      int       `json:"id"`
+	Hash    string    `json:"hash"`
+	Name    string    `json:"name"`
+	Details string    `json:"details,omitempty"`
+	DryRun  bool      `json:"dryRun,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Engine evaluates rules against the torrent list and takes their actions.
+type Engine struct {
+	client transmission.API
+	rules  []compiledRule
+	// fields are the torrent fields the rules and their actions read
+	fields  []string
+	dryRun  bool
+	mu      sync.Mutex
+	audit   io.Writer
+	onError func(error)
+	now     func() time.Time
+}
+
+type Option func(*Engine)
+
+// DryRunOption only records the actions in the audit log, without taking
+// them.
+func DryRunOption() Option {
+	return func(e *Engine) {
+		e.dryRun = true
+	}
+}
+
+// AuditLogOption writes every audit entry to w as a line of JSON.
+func AuditLogOption(w io.Writer) Option {
+	return func(e *Engine) {
+		e.audit = w
+	}
+}
+
+// RunErrorOption is called by Run with the error of every run that failed,
+// after it is recorded in the audit log.
+func RunErrorOption(onError func(error)) Option {
+	return func(e *Engine) {
+		e.onError = onError
+	}
+}
+
+// baseFields are read for every torrent, to identify it in the audit log.
+var baseFields = []string{"id", "hashString", "name"}
+
+// actionFields are the torrent fields an action reads to skip torrents it
+// wouldn't change.
+var actionFields = map[string][]string{
+	ActionStop:   {"status"},
+	ActionMove:   {"downloadDir"},
+	ActionLabel:  {"labels"},
+	ActionLimits: {"downloadLimit", "downloadLimited", "uploadLimit", "uploadLimited", "seedRatioLimit", "seedRatioMode"},
+}
+
+// NewEngine checks the rules and returns an engine running them through
+// client.
+func NewEngine(client transmission.API, rules []Rule, opts ...Option) (*Engine, error) {
+	e := &Engine{
+		client: client,
+		now:    time.Now,
+	}
+	fields := append([]string{}, baseFields...)
+	for _, rule := range rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, compiled)
+		fields = append(fields, compiled.query.Fields()...)
+		for _, action := range rule.Actions {
+			fields = append(fields, actionFields[action.Type]...)
+		}
+	}
+	for _, field := range fields {
+		if !contains(e.fields, field) {
+			e.fields = append(e.fields, field)
+		}
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// Run evaluates the rules every interval until ctx is done, and returns the
+// context's error. Failed runs are recorded in the audit log, passed to the
+// RunErrorOption callback and retried at the next interval.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := e.RunOnce(ctx); err != nil && e.onError != nil && ctx.Err() == nil {
+			e.onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce evaluates every rule once, in order, and returns the audit entries
+// of the run. A torrent removed by a rule isn't seen by the rules after it.
+// The error is the first failure, but every action is attempted. Only the
+// torrent fields the rules read are requested.
+func (e *Engine) RunOnce(ctx context.Context) ([]AuditEntry, error) {
+	var torrents []transmission.Torrent
+	err := e.client.IterateTorrents(ctx, func(torrent transmission.Torrent) error {
+		torrents = append(torrents, torrent)
+		return nil
+	}, transmission.TorrentFieldsOption(e.fields...))
+	if err != nil {
+		e.record(AuditEntry{Time: e.now(), Action: "list", Error: err.Error()})
+		return nil, err
+	}
+	var entries []AuditEntry
+	var firstErr error
+	removed := make(map[int]bool)
+	for _, rule := range e.rules {
+		var matched []transmission.Torrent
+		for i := range torrents {
+			if !removed[torrents[i].ID] && rule.query.Match(&torrents[i]) {
+				matched = append(matched, torrents[i])
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		for _, action := range rule.Actions {
+			ruleEntries, err := e.apply(ctx, rule.Name, action, matched)
+			entries = append(entries, ruleEntries...)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			if action.Type == ActionRemove && err == nil {
+				for _, torrent := range matched {
+					removed[torrent.ID] = true
+				}
+			}
+		}
+	}
+	return entries, firstErr
+}
+
+// apply takes one action on the torrents, skipping those it wouldn't change.
+func (e *Engine) apply(ctx context.Context, rule string, action Action, torrents []transmission.Torrent) ([]AuditEntry, error) {
+	if action.Type == ActionLabel {
+		return e.label(ctx, rule, action, torrents)
+	}
+	var targets []transmission.Torrent
+	for _, torrent := range torrents {
+		if changes(action, &torrent) {
+			targets = append(targets, torrent)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	ids := make([]transmission.TorrentID, len(targets))
+	for i, torrent := range targets {
+		ids[i] = transmission.ID(torrent.ID)
+	}
+	var err error
+	if !e.dryRun {
+		err = e.call(ctx, action, ids)
+	}
+	entries := make([]AuditEntry, len(targets))
+	for i, torrent := range targets {
+		entries[i] = e.entry(rule, action, &torrent, details(action), err)
+	}
+	return entries, err
+}
+
+func (e *Engine) call(ctx context.Context, action Action, ids []transmission.TorrentID) error {
+	switch action.Type {
+	case ActionStop:
+		return e.client.StopTorrents(ctx, ids...)
+	case ActionRemove:
+		return e.client.RemoveTorrents(ctx, action.DeleteData, ids...)
+	case ActionMove:
+		return e.client.SetTorrentLocation(ctx, action.Location, !action.KeepData, ids...)
+	case ActionReannounce:
+		return e.client.ReannounceTorrents(ctx, ids...)
+	case ActionQueue:
+		switch action.Queue {
+		case "top":
+			return e.client.QueueMoveTop(ctx, ids...)
+		case "up":
+			return e.client.QueueMoveUp(ctx, ids...)
+		case "down":
+			return e.client.QueueMoveDown(ctx, ids...)
+		}
+		return e.client.QueueMoveBottom(ctx, ids...)
+	}
+	var opts []transmission.SetTorrentsOption
+	if action.DownloadLimit != nil {
+		if *action.DownloadLimit < 0 {
+			opts = append(opts, transmission.NoDownloadLimitOption())
+		} else {
+			opts = append(opts, transmission.DownloadLimitOption(*action.DownloadLimit))
+		}
+	}
+	if action.UploadLimit != nil {
+		if *action.UploadLimit < 0 {
+			opts = append(opts, transmission.NoUploadLimitOption())
+		} else {
+			opts = append(opts, transmission.UploadLimitOption(*action.UploadLimit))
+		}
+	}
+	if action.SeedRatioLimit != nil {
+		opts = append(opts, transmission.SeedRatioLimitOption(*action.SeedRatioLimit))
+	}
+	return e.client.SetTorrents(ctx, ids, opts...)
+}
+
+// label adds the labels torrent by torrent, as torrent-set replaces the whole
+// list.
+func (e *Engine) label(ctx context.Context, rule string, action Action, torrents []transmission.Torrent) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	var firstErr error
+	for _, torrent := range torrents {
+		labels := append([]string{}, torrent.Labels...)
+		for _, label := range action.Labels {
+			if !contains(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+		if len(labels) == len(torrent.Labels) {
+			continue
+		}
+		var err error
+		if !e.dryRun {
+			err = e.client.SetTorrents(ctx, []transmission.TorrentID{transmission.ID(torrent.ID)}, transmission.LabelsOption(labels...))
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		entries = append(entries, e.entry(rule, action, &torrent, details(action), err))
+	}
+	return entries, firstErr
+}
+
+// changes is false for actions that would leave the torrent as it is.
+func changes(action Action, torrent *transmission.Torrent) bool {
+	switch action.Type {
+	case ActionStop:
+		return torrent.Status != transmission.StatusStopped
+	case ActionMove:
+		return torrent.DownloadDir != action.Location
+	case ActionLimits:
+		if action.DownloadLimit != nil && (*action.DownloadLimit < 0 && torrent.DownloadLimited ||
+			*action.DownloadLimit >= 0 && (!torrent.DownloadLimited || torrent.DownloadLimit != *action.DownloadLimit)) {
+			return true
+		}
+		if action.UploadLimit != nil && (*action.UploadLimit < 0 && torrent.UploadLimited ||
+			*action.UploadLimit >= 0 && (!torrent.UploadLimited || torrent.UploadLimit != *action.UploadLimit)) {
+			return true
+		}
+		return action.SeedRatioLimit != nil && (torrent.SeedRatioMode != 1 || torrent.SeedRatioLimit != *action.SeedRatioLimit)
+	}
+	return true
+}
+
+func details(action Action) string {
+	switch action.Type {
+	case ActionMove:
+		return action.Location
+	case ActionQueue:
+		return action.Queue
+	case ActionRemove:
+		if action.DeleteData {
+			return "with data"
+		}
+	case ActionLabel:
+		return strings.Join(action.Labels, ",")
+	case ActionLimits:
+		encoded, _ := json.Marshal(Action{
+			DownloadLimit:  action.DownloadLimit,
+			UploadLimit:    action.UploadLimit,
+			SeedRatioLimit: action.SeedRatioLimit,
+		})
+		return string(encoded)
+	}
+	return ""
+}
+
+func (e *Engine) entry(rule string, action Action, torrent *transmission.Torrent, details string, err error) AuditEntry {
+	entry := AuditEntry{
+		Time:    e.now(),
+		Rule:    rule,
+		Action:  action.Type,
+		ID:      torrent.ID,
+		Hash:    torrent.HashString,
+		Name:    torrent.Name,
+		Details: details,
+		DryRun:  e.dryRun,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	e.record(entry)
+	return entry
+}
+
+func (e *Engine) record(entry AuditEntry) {
+	if e.audit == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	json.NewEncoder(e.audit).Encode(entry)
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
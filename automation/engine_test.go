@@ -0,0 +1,134 @@
+package automation_test
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/automation"
+	"github.com/bobcob7/transmission-rpc/transmissiontest"
+)
+
+func newEngine(t *testing.T, rules []automation.Rule, opts ...automation.Option) (*automation.Engine, *transmissiontest.Server) {
+	t.Helper()
+	server := transmissiontest.NewServer()
+	t.Cleanup(server.Close)
+	client, err := transmission.New(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	engine, err := automation.NewEngine(client, rules, opts...)
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	return engine, server
+}
+
+func TestRunOnceRequestsRuleFields(t *testing.T) {
+	rules := []automation.Rule{
+		{Name: "file tv", When: "label=tv and percentDone=1", Actions: []automation.Action{{Type: automation.ActionMove, Location: "/tv"}}},
+		{Name: "kick stalled", When: "isStalled", Actions: []automation.Action{{Type: automation.ActionReannounce}}},
+	}
+	engine, server := newEngine(t, rules)
+	var torrent transmission.Torrent
+	torrent.Labels = []string{"tv"}
+	torrent.PercentDone = 1
+	id := server.AddTorrent(torrent)
+	entries, err := engine.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != id || entries[0].Action != automation.ActionMove {
+		t.Errorf("got audit entries %+v, want torrent %d moved", entries, id)
+	}
+
+	var fields []string
+	for _, req := range server.Requests() {
+		if req.Method != "torrent-get" {
+			continue
+		}
+		var args struct {
+			Fields []string `json:"fields"`
+		}
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			t.Fatalf("decoding torrent-get arguments: %v", err)
+		}
+		fields = args.Fields
+	}
+	sort.Strings(fields)
+	want := []string{"downloadDir", "hashString", "id", "isStalled", "labels", "name", "percentDone"}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("requested fields %v, want %v", fields, want)
+	}
+}
+
+func TestRunReportsErrors(t *testing.T) {
+	errs := make(chan error, 10)
+	rules := []automation.Rule{{Name: "stop all", When: automation.WhenAll, Actions: []automation.Action{{Type: automation.ActionStop}}}}
+	engine, server := newEngine(t, rules, automation.RunErrorOption(func(err error) {
+		errs <- err
+	}))
+	server.FailMethod("torrent-get", "busy", 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- engine.Run(ctx, time.Hour)
+	}()
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "busy") {
+			t.Errorf("got error %v, want the failed torrent-get", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the failed run wasn't reported")
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run returned %v, want context.Canceled", err)
+	}
+}
+
+func TestRulesNeedWhenToStopRemoveOrMove(t *testing.T) {
+	server := transmissiontest.NewServer()
+	defer server.Close()
+	client, err := transmission.New(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, action := range []automation.Action{
+		{Type: automation.ActionStop},
+		{Type: automation.ActionRemove},
+		{Type: automation.ActionMove, Location: "/elsewhere"},
+	} {
+		rules := []automation.Rule{{Name: action.Type, Actions: []automation.Action{action}}}
+		if _, err := automation.NewEngine(client, rules); err == nil {
+			t.Errorf("a %s rule without when was accepted", action.Type)
+		}
+	}
+	rules := []automation.Rule{{Name: "kick everything", Actions: []automation.Action{{Type: automation.ActionReannounce}}}}
+	if _, err := automation.NewEngine(client, rules); err != nil {
+		t.Errorf("a reannounce rule without when was refused: %v", err)
+	}
+}
+
+func TestWhenAllMatchesEveryTorrent(t *testing.T) {
+	rules := []automation.Rule{{Name: "stop all", When: automation.WhenAll, Actions: []automation.Action{{Type: automation.ActionStop}}}}
+	engine, server := newEngine(t, rules)
+	for i := 0; i < 2; i++ {
+		var torrent transmission.Torrent
+		torrent.Status = transmission.StatusDownload
+		server.AddTorrent(torrent)
+	}
+	entries, err := engine.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("got audit entries %+v, want both torrents stopped", entries)
+	}
+}
@@ -0,0 +1,192 @@
+// Package automation runs housekeeping rules against the torrents of a
+// Transmission server.
+//
+// Rules are written in JSON. Each rule selects torrents with a filter in the
+// query language of transmission.ParseQuery and lists the actions to take on
+// them:
+//
+//	{
+//		"interval": "10m",
+//		"rules": [
+//			{
+//				"name": "retire public torrents",
+//				"when": "not isPrivate and (uploadRatio>=2 or secondsSeeding>=14d)",
+//				"actions": [{"type": "remove", "deleteData": true}]
+//			},
+//			{
+//				"name": "file tv",
+//				"when": "label=tv and leftUntilDone=0 and metadataPercentComplete=1",
+//				"actions": [{"type": "move", "location": "/library/tv"}]
+//			},
+//			{
+//				"name": "kick stalled",
+//				"when": "isStalled",
+//				"actions": [{"type": "reannounce"}]
+//			}
+//		]
+//	}
+//
+// A rule that stops, removes or moves torrents needs a when, "all" to act on
+// every torrent.
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+// Action types
+const (
+	ActionStop       = "stop"
+	ActionRemove     = "remove"
+	ActionMove       = "move"
+	ActionLabel      = "label"
+	ActionLimits     = "limits"
+	ActionQueue      = "queue"
+	ActionReannounce = "reannounce"
+)
+
+// WhenAll is the When of rules acting on every torrent.
+const WhenAll = "all"
+
+// Config is a set of rules and how often to run them.
+type Config struct {
+	// Interval is a duration like 10m, 5 minutes if empty
+	Interval string `json:"interval,omitempty"`
+	Rules    []Rule `json:"rules"`
+}
+
+// Rule takes actions on the torrents matching a filter.
+type Rule struct {
+	Name string `json:"name"`
+	// When is a transmission.ParseQuery filter, or WhenAll to match every
+	// torrent. It can only be left empty, also matching every torrent, when
+	// none of the actions stop, remove or move torrents.
+	When    string   `json:"when"`
+	Actions []Action `json:"actions"`
+}
+
+// Action is one change made to the torrents of a rule. Which fields apply
+// depends on the type.
+type Action struct {
+	Type string `json:"type"`
+	// DeleteData removes the data along with the torrent, for remove
+	DeleteData bool `json:"deleteData,omitempty"`
+	// Location is the new download directory, for move
+	Location string `json:"location,omitempty"`
+	// KeepData changes the download directory without moving the data, for move
+	KeepData bool `json:"keepData,omitempty"`
+	// Labels are added to the torrent's labels, for label
+	Labels []string `json:"labels,omitempty"`
+	// DownloadLimit and UploadLimit are in KB/s with -1 for no limit, and
+	// SeedRatioLimit stops seeding at a ratio, for limits
+	DownloadLimit  *int     `json:"downloadLimit,omitempty"`
+	UploadLimit    *int     `json:"uploadLimit,omitempty"`
+	SeedRatioLimit *float64 `json:"seedRatioLimit,omitempty"`
+	// Queue is top, up, down or bottom, for queue
+	Queue string `json:"queue,omitempty"`
+}
+
+// ParseConfig reads a JSON config.
+func ParseConfig(r io.Reader) (*Config, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to decode rules: %w", err)
+	}
+	return &config, nil
+}
+
+// LoadConfig reads a JSON config file.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseConfig(f)
+}
+
+// IntervalDuration returns the parsed interval.
+func (c *Config) IntervalDuration() (time.Duration, error) {
+	if c.Interval == "" {
+		return defaultInterval, nil
+	}
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval: %w", err)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("invalid interval %s", c.Interval)
+	}
+	return interval, nil
+}
+
+const defaultInterval = 5 * time.Minute
+
+type compiledRule struct {
+	Rule
+	query *transmission.Query
+}
+
+func compileRule(rule Rule) (compiledRule, error) {
+	when := rule.When
+	if when == WhenAll {
+		when = ""
+	}
+	query, err := transmission.ParseQuery(when)
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("rule %q: %w", rule.Name, err)
+	}
+	if len(rule.Actions) == 0 {
+		return compiledRule{}, fmt.Errorf("rule %q has no actions", rule.Name)
+	}
+	for _, action := range rule.Actions {
+		if err := action.validate(); err != nil {
+			return compiledRule{}, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		if rule.When == "" && action.destructive() {
+			return compiledRule{}, fmt.Errorf("rule %q would %s every torrent, set when to %q if that is intended", rule.Name, action.Type, WhenAll)
+		}
+	}
+	return compiledRule{Rule: rule, query: query}, nil
+}
+
+// destructive reports whether the action stops, removes or moves torrents,
+// which rules only do to every torrent when asked to explicitly.
+func (a Action) destructive() bool {
+	return a.Type == ActionStop || a.Type == ActionRemove || a.Type == ActionMove
+}
+
+func (a Action) validate() error {
+	switch a.Type {
+	case ActionStop, ActionRemove, ActionReannounce:
+	case ActionMove:
+		if a.Location == "" {
+			return fmt.Errorf("move action needs a location")
+		}
+	case ActionLabel:
+		if len(a.Labels) == 0 {
+			return fmt.Errorf("label action needs labels")
+		}
+	case ActionLimits:
+		if a.DownloadLimit == nil && a.UploadLimit == nil && a.SeedRatioLimit == nil {
+			return fmt.Errorf("limits action needs downloadLimit, uploadLimit or seedRatioLimit")
+		}
+	case ActionQueue:
+		switch a.Queue {
+		case "top", "up", "down", "bottom":
+		default:
+			return fmt.Errorf("queue action needs a queue of top, up, down or bottom, got %q", a.Queue)
+		}
+	default:
+		return fmt.Errorf("unknown action type %q", a.Type)
+	}
+	return nil
+}
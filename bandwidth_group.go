@@ -0,0 +1,59 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+)
+
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md#48-bandwidth-groups
+type BandwidthGroup struct {
+	Name                string `json:"name"`
+	HonorsSessionLimits bool   `json:"honorsSessionLimits"`
+	// Speed limits are in kB/s
+	SpeedLimitDownEnabled bool `json:"speed-limit-down-enabled"`
+	SpeedLimitDown        int  `json:"speed-limit-down"`
+	SpeedLimitUpEnabled   bool `json:"speed-limit-up-enabled"`
+	SpeedLimitUp          int  `json:"speed-limit-up"`
+}
+
+type getBandwidthGroupsRequestArgs struct {
+	Group []string `json:"group,omitempty"`
+}
+
+type getBandwidthGroupsResponse struct {
+	Result    string                         `json:"result"`
+	Arguments getBandwidthGroupsResponseArgs `json:"arguments"`
+	Tag       int64                          `json:"tag"`
+}
+
+type getBandwidthGroupsResponseArgs struct {
+	Group []BandwidthGroup `json:"group"`
+}
+
+// GetBandwidthGroups returns the named bandwidth groups, or every group if no
+// names are given.
+func (t *Client) GetBandwidthGroups(ctx context.Context, names ...string) ([]BandwidthGroup, error) {
+	var response getBandwidthGroupsResponse
+	req := getBandwidthGroupsRequestArgs{
+		Group: names,
+	}
+	if err := t.callRPC(ctx, "group-get", &req, &response); err != nil {
+		return nil, err
+	}
+	return response.Arguments.Group, nil
+}
+
+// SetBandwidthGroup creates or updates the bandwidth group named group.Name.
+func (t *Client) SetBandwidthGroup(ctx context.Context, group BandwidthGroup) error {
+	var response genericResponse
+	return t.callRPC(ctx, "group-set", &group, &response)
+}
+
+// AssignBandwidthGroup moves the given torrents into the named bandwidth
+// group. An empty name removes them from their group.
+func (t *Client) AssignBandwidthGroup(ctx context.Context, group string, ids ...TorrentID) error {
+	if len(ids) == 0 {
+		return errors.New("no torrents to assign")
+	}
+	return t.SetTorrents(ctx, ids, GroupOption(group))
+}
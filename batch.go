@@ -0,0 +1,142 @@
+package transmission
+
+import (
+	"context"
+	"sync"
+)
+
+const defaultBatchConcurrency = 8
+
+// Batch queues torrent operations and runs them concurrently over the
+// client's keep-alive connections.
+//
+//	b := client.Batch()
+//	b.Start(ids...)
+//	b.SetTorrents(ids, LabelsOption("tv"))
+//	results := b.Do(ctx)
+type Batch struct {
+	// Concurrency is the maximum number of calls in flight, 8 by default.
+	Concurrency int
+	client      TorrentWriter
+	operations  []batchOperation
+}
+
+type batchOperation struct {
+	method string
+	ids    []TorrentID
+	call   func(ctx context.Context) error
+}
+
+// BatchResult is the outcome of one queued operation.
+type BatchResult struct {
+	Method string
+	IDs    []TorrentID
+	Err    error
+}
+
+// BatchResults are returned in the order the operations were queued.
+type BatchResults []BatchResult
+
+// Err returns the first error of the batch, if any.
+func (r BatchResults) Err() error {
+	for _, result := range r {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return nil
+}
+
+func (t *Client) Batch() *Batch {
+	return NewBatch(t)
+}
+
+// NewBatch queues operations for any TorrentWriter, such as a wrapped client.
+func NewBatch(client TorrentWriter) *Batch {
+	return &Batch{
+		Concurrency: defaultBatchConcurrency,
+		client:      client,
+	}
+}
+
+func (b *Batch) add(method string, ids []TorrentID, call func(ctx context.Context) error) *Batch {
+	b.operations = append(b.operations, batchOperation{
+		method: method,
+		ids:    ids,
+		call:   call,
+	})
+	return b
+}
+
+func (b *Batch) Start(ids ...TorrentID) *Batch {
+	return b.add("torrent-start", ids, func(ctx context.Context) error {
+		return b.client.StartTorrents(ctx, ids...)
+	})
+}
+
+func (b *Batch) Stop(ids ...TorrentID) *Batch {
+	return b.add("torrent-stop", ids, func(ctx context.Context) error {
+		return b.client.StopTorrents(ctx, ids...)
+	})
+}
+
+func (b *Batch) Verify(ids ...TorrentID) *Batch {
+	return b.add("torrent-verify", ids, func(ctx context.Context) error {
+		return b.client.VerifyTorrents(ctx, ids...)
+	})
+}
+
+func (b *Batch) Reannounce(ids ...TorrentID) *Batch {
+	return b.add("torrent-reannounce", ids, func(ctx context.Context) error {
+		return b.client.ReannounceTorrents(ctx, ids...)
+	})
+}
+
+func (b *Batch) SetTorrents(ids []TorrentID, opts ...SetTorrentsOption) *Batch {
+	return b.add("torrent-set", ids, func(ctx context.Context) error {
+		return b.client.SetTorrents(ctx, ids, opts...)
+	})
+}
+
+func (b *Batch) Remove(deleteLocalData bool, ids ...TorrentID) *Batch {
+	return b.add("torrent-remove", ids, func(ctx context.Context) error {
+		return b.client.RemoveTorrents(ctx, deleteLocalData, ids...)
+	})
+}
+
+// Len returns the number of queued operations.
+func (b *Batch) Len() int {
+	return len(b.operations)
+}
+
+// Do runs every queued operation and waits for them to finish. Operations
+// that haven't started when ctx is done fail with the context's error.
+func (b *Batch) Do(ctx context.Context) BatchResults {
+	concurrency := b.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	results := make(BatchResults, len(b.operations))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, op := range b.operations {
+		results[i] = BatchResult{
+			Method: op.method,
+			IDs:    op.ids,
+		}
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(result *BatchResult, op batchOperation) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			result.Err = op.call(ctx)
+		}(&results[i], op)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,102 @@
+// Package bencode encodes and decodes the bencoding of BitTorrent metainfo
+// files.
+//
+// Byte strings map to string and []byte, integers to the integer types and
+// bool, lists to slices and arrays, and dictionaries to structs and maps with
+// string keys. Struct fields are named with `bencode:"name,omitempty"` tags,
+// and fields without a tag use the field name. Decoding into an interface{}
+// gives string, int64, []interface{} and map[string]interface{} values.
+//
+// Decoding only accepts the canonical encoding: integers without leading
+// zeros or -0, and dictionary keys unique and sorted. Lists and dictionaries
+// can nest 256 deep.
+package bencode
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RawMessage is a raw encoded value. It is copied as is when encoding, and
+// holds the exact bytes of the value when decoding, such as the info
+// dictionary a torrent's info hash is computed over.
+type RawMessage []byte
+
+// SyntaxError is malformed bencoding.
+type SyntaxError struct {
+	Offset int
+	msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("bencode: %s at offset %d", e.msg, e.Offset)
+}
+
+// UnmarshalTypeError is a value that can't be decoded into the Go type.
+type UnmarshalTypeError struct {
+	Value  string
+	Type   reflect.Type
+	Offset int
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("bencode: cannot decode %s into Go value of type %s at offset %d", e.Value, e.Type, e.Offset)
+}
+
+var rawMessageType = reflect.TypeOf(RawMessage{})
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var fieldCache sync.Map
+
+// structFields returns the encoded fields of a struct type, sorted by name as
+// dictionaries are encoded.
+func structFields(t reflect.Type) []field {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field)
+	}
+	var fields []field
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			fieldIndex := append(append([]int{}, index...), i)
+			tag := f.Tag.Get("bencode")
+			if tag == "-" {
+				continue
+			}
+			if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+				walk(f.Type, fieldIndex)
+				continue
+			}
+			if f.PkgPath != "" {
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			name := parts[0]
+			if name == "" {
+				name = f.Name
+			}
+			omitEmpty := false
+			for _, option := range parts[1:] {
+				if option == "omitempty" {
+					omitEmpty = true
+				}
+			}
+			fields = append(fields, field{name: name, index: fieldIndex, omitEmpty: omitEmpty})
+		}
+	}
+	walk(t, nil)
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].name < fields[j].name
+	})
+	fieldCache.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,186 @@
+package bencode_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc/bencode"
+)
+
+type embedded struct {
+	Comment string `bencode:"comment,omitempty"`
+}
+
+type document struct {
+	embedded
+	Name    string             `bencode:"name"`
+	Length  int64              `bencode:"length"`
+	Private bool               `bencode:"private,omitempty"`
+	Hash    [4]byte            `bencode:"hash"`
+	Pieces  []byte             `bencode:"pieces"`
+	Tiers   [][]string         `bencode:"announce-list,omitempty"`
+	Extra   map[string]int     `bencode:"extra,omitempty"`
+	Raw     bencode.RawMessage `bencode:"raw,omitempty"`
+	Skipped string             `bencode:"-"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	doc := document{
+		embedded: embedded{Comment: "test"},
+		Name:     "debian.iso",
+		Length:   -42,
+		Private:  true,
+		Hash:     [4]byte{0, 1, 2, 0xff},
+		Pieces:   []byte("\x00\x01binary"),
+		Tiers:    [][]string{{"http://a/announce", "http://b/announce"}, {"udp://c:80"}},
+		Extra:    map[string]int{"b": 2, "a": 1},
+		Raw:      bencode.RawMessage("li1ei2ee"),
+		Skipped:  "not encoded",
+	}
+	data, err := bencode.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got document
+	if err := bencode.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal(%q): %v", data, err)
+	}
+	doc.Skipped = ""
+	if !reflect.DeepEqual(got, doc) {
+		t.Errorf("round trip gave %+v, want %+v", got, doc)
+	}
+	again, err := bencode.Marshal(got)
+	if err != nil || string(again) != string(data) {
+		t.Errorf("encoding again gave %q, %v, want %q", again, err, data)
+	}
+}
+
+func TestMarshalSortsKeys(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{map[string]interface{}{"zebra": 1, "apple": "x", "mango": []int{1}}, "d5:apple1:x5:mangoli1ee5:zebrai1ee"},
+		// Keys sort as raw bytes, so upper case comes first
+		{map[string]int{"b": 1, "B": 2, "a": 3}, "d1:Bi2e1:ai3e1:bi1ee"},
+		{document{Name: "n", Hash: [4]byte{'a', 'b', 'c', 'd'}, Pieces: []byte{}}, "d4:hash4:abcd6:lengthi0e4:name1:n6:pieces0:e"},
+		{struct {
+			Zed int `bencode:"z"`
+			Aye int `bencode:"a"`
+		}{1, 2}, "d1:ai2e1:zi1ee"},
+	}
+	for _, tt := range tests {
+		got, err := bencode.Marshal(tt.value)
+		if err != nil {
+			t.Errorf("Marshal(%+v): %v", tt.value, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("Marshal(%+v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalGeneric(t *testing.T) {
+	var got interface{}
+	if err := bencode.Unmarshal([]byte("d1:ali-3e4:spame1:bi0e1:cdee"), &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := map[string]interface{}{
+		"a": []interface{}{int64(-3), "spam"},
+		"b": int64(0),
+		"c": map[string]interface{}{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestUnmarshalRejectsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"leading zero":            "i03e",
+		"negative zero":           "i-0e",
+		"negative leading zero":   "i-01e",
+		"empty integer":           "ie",
+		"bare minus":              "i-e",
+		"invalid integer":         "i1x2e",
+		"string length leading 0": "01:a",
+		"unsorted keys":           "d1:bi1e1:ai2ee",
+		"duplicate keys":          "d1:ai1e1:ai2ee",
+		"nested unsorted keys":    "d1:ad1:yi1e1:xi2eee",
+		"non string key":          "di1ei2ee",
+		"data after value":        "i1ei2e",
+		"invalid value":           "x",
+		"empty":                   "",
+		"truncated integer":       "i12",
+		"truncated string":        "5:abc",
+		"truncated string length": "12",
+		"truncated list":          "li1e",
+		"truncated dictionary":    "d1:ai1e",
+		"truncated key":           "d3:ab",
+		"missing value":           "d1:ae",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var generic interface{}
+			if err := bencode.Unmarshal([]byte(data), &generic); err == nil {
+				t.Errorf("Unmarshal(%q) into interface{} succeeded", data)
+			}
+			var raw bencode.RawMessage
+			if err := bencode.Unmarshal([]byte(data), &raw); err == nil {
+				t.Errorf("Unmarshal(%q) into RawMessage succeeded", data)
+			}
+		})
+	}
+	var doc document
+	if err := bencode.Unmarshal([]byte("d4:name1:n6:lengthi1ee"), &doc); err == nil {
+		t.Error("Unmarshal into a struct accepted unsorted keys")
+	}
+	if err := bencode.Unmarshal([]byte("d4:name1:a4:name1:be"), &doc); err == nil {
+		t.Error("Unmarshal into a struct accepted duplicate keys")
+	}
+}
+
+func TestUnmarshalTypeErrors(t *testing.T) {
+	var doc document
+	tests := []struct {
+		data string
+		v    interface{}
+	}{
+		{"i1e", &doc},
+		{"d4:name3:abce", doc},
+		{"d4:hash3:abce", &doc},
+		{"d4:namei1ee", &doc},
+		{"i300e", new(int8)},
+		{"i-1e", new(uint)},
+		{"d1:a1:be", new(map[int]string)},
+	}
+	for _, tt := range tests {
+		if err := bencode.Unmarshal([]byte(tt.data), tt.v); err == nil {
+			t.Errorf("Unmarshal(%q) into %T succeeded", tt.data, tt.v)
+		}
+	}
+}
+
+func TestUnmarshalNestingDepth(t *testing.T) {
+	nested := func(depth int) []byte {
+		return []byte(strings.Repeat("l", depth) + strings.Repeat("e", depth))
+	}
+	var generic interface{}
+	if err := bencode.Unmarshal(nested(256), &generic); err != nil {
+		t.Errorf("256 nested lists: %v", err)
+	}
+	if err := bencode.Unmarshal(nested(257), &generic); err == nil {
+		t.Error("257 nested lists were accepted")
+	}
+	var raw bencode.RawMessage
+	if err := bencode.Unmarshal(nested(1<<20), &raw); err == nil {
+		t.Error("a million nested lists were accepted")
+	}
+	var dicts interface{}
+	data := strings.Repeat("d1:a", 1000) + "i1e" + strings.Repeat("e", 1000)
+	if err := bencode.Unmarshal([]byte(data), &dicts); err == nil {
+		t.Error("1000 nested dictionaries were accepted")
+	}
+}
@@ -0,0 +1,421 @@
+package bencode
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+)
+
+// Unmarshal decodes the bencoded data into v, which must be a non-nil
+// pointer. Dictionary keys without a matching field are skipped.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &UnmarshalTypeError{Value: "value", Type: reflect.TypeOf(v)}
+	}
+	d := &decoder{data: data}
+	if err := d.value(rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return d.syntaxError("data after the top level value")
+	}
+	return nil
+}
+
+// maxDepth caps how deeply lists and dictionaries nest, so hostile input
+// can't exhaust the stack.
+const maxDepth = 256
+
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// enter starts decoding a list or dictionary, which leave ends.
+func (d *decoder) enter() error {
+	if d.depth >= maxDepth {
+		return d.syntaxError("lists and dictionaries nested too deeply")
+	}
+	d.depth++
+	return nil
+}
+
+func (d *decoder) leave() {
+	d.depth--
+}
+
+// dictKey reads a dictionary key, which has to sort after the previous one:
+// keys are unique and in order, so each dictionary has a single encoding.
+func (d *decoder) dictKey(previous []byte, first bool) ([]byte, error) {
+	start := d.pos
+	key, err := d.readString()
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		switch c := bytes.Compare(key, previous); {
+		case c == 0:
+			return nil, &SyntaxError{Offset: start, msg: "duplicate dictionary key " + strconv.Quote(string(key))}
+		case c < 0:
+			return nil, &SyntaxError{Offset: start, msg: "unsorted dictionary key " + strconv.Quote(string(key))}
+		}
+	}
+	return key, nil
+}
+
+func (d *decoder) syntaxError(msg string) error {
+	return &SyntaxError{Offset: d.pos, msg: msg}
+}
+
+func (d *decoder) peek() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, d.syntaxError("unexpected end of data")
+	}
+	return d.data[d.pos], nil
+}
+
+// value decodes the next value into v.
+func (d *decoder) value(v reflect.Value) error {
+	if v.Type() == rawMessageType {
+		start := d.pos
+		if err := d.skip(); err != nil {
+			return err
+		}
+		v.SetBytes(append([]byte{}, d.data[start:d.pos]...))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.value(v.Elem())
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		generic, err := d.generic()
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(generic))
+		return nil
+	}
+	c, err := d.peek()
+	if err != nil {
+		return err
+	}
+	switch {
+	case c == 'i':
+		return d.integer(v)
+	case c >= '0' && c <= '9':
+		return d.byteString(v)
+	case c == 'l':
+		return d.list(v)
+	case c == 'd':
+		return d.dict(v)
+	}
+	return d.syntaxError("invalid value")
+}
+
+func (d *decoder) typeError(value string, v reflect.Value, offset int) error {
+	return &UnmarshalTypeError{Value: value, Type: v.Type(), Offset: offset}
+}
+
+// readInt reads an integer up to the terminator, rejecting leading zeros and
+// negative zero as the spec requires.
+func (d *decoder) readInt(terminator byte) (string, error) {
+	start := d.pos
+	for d.pos < len(d.data) && d.data[d.pos] != terminator {
+		d.pos++
+	}
+	if d.pos >= len(d.data) {
+		return "", d.syntaxError("unterminated integer")
+	}
+	digits := string(d.data[start:d.pos])
+	d.pos++
+	unsigned := digits
+	if len(unsigned) > 0 && unsigned[0] == '-' {
+		unsigned = unsigned[1:]
+		if unsigned == "0" {
+			return "", &SyntaxError{Offset: start, msg: "negative zero"}
+		}
+	}
+	if unsigned == "" {
+		return "", &SyntaxError{Offset: start, msg: "empty integer"}
+	}
+	for _, c := range unsigned {
+		if c < '0' || c > '9' {
+			return "", &SyntaxError{Offset: start, msg: "invalid integer " + strconv.Quote(digits)}
+		}
+	}
+	if len(unsigned) > 1 && unsigned[0] == '0' {
+		return "", &SyntaxError{Offset: start, msg: "leading zero in integer"}
+	}
+	return digits, nil
+}
+
+func (d *decoder) integer(v reflect.Value) error {
+	start := d.pos
+	d.pos++
+	digits, err := d.readInt('e')
+	if err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil || v.OverflowInt(n) {
+			return d.typeError("integer "+digits, v, start)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(digits, 10, 64)
+		if err != nil || v.OverflowUint(n) {
+			return d.typeError("integer "+digits, v, start)
+		}
+		v.SetUint(n)
+	case reflect.Bool:
+		v.SetBool(digits != "0")
+	default:
+		return d.typeError("integer", v, start)
+	}
+	return nil
+}
+
+func (d *decoder) readString() ([]byte, error) {
+	start := d.pos
+	digits, err := d.readInt(':')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 0 {
+		return nil, &SyntaxError{Offset: start, msg: "invalid string length"}
+	}
+	if n > len(d.data)-d.pos {
+		return nil, d.syntaxError("string longer than the data")
+	}
+	s := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return s, nil
+}
+
+func (d *decoder) byteString(v reflect.Value) error {
+	start := d.pos
+	s, err := d.readString()
+	if err != nil {
+		return err
+	}
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(string(s))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes(append([]byte{}, s...))
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+		if len(s) != v.Len() {
+			return d.typeError("string of "+strconv.Itoa(len(s))+" bytes", v, start)
+		}
+		reflect.Copy(v, reflect.ValueOf(s))
+	default:
+		return d.typeError("string", v, start)
+	}
+	return nil
+}
+
+func (d *decoder) list(v reflect.Value) error {
+	start := d.pos
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	d.pos++
+	switch v.Kind() {
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	case reflect.Array:
+	default:
+		return d.typeError("list", v, start)
+	}
+	for i := 0; ; i++ {
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		if c == 'e' {
+			d.pos++
+			return nil
+		}
+		if v.Kind() == reflect.Array {
+			if i >= v.Len() {
+				return d.typeError("longer list", v, start)
+			}
+			if err := d.value(v.Index(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := d.value(elem); err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, elem))
+	}
+}
+
+func (d *decoder) dict(v reflect.Value) error {
+	start := d.pos
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	d.pos++
+	var fields map[string]field
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return d.typeError("dictionary", v, start)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	case reflect.Struct:
+		fields = make(map[string]field)
+		for _, f := range structFields(v.Type()) {
+			fields[f.name] = f
+		}
+	default:
+		return d.typeError("dictionary", v, start)
+	}
+	var key []byte
+	for first := true; ; first = false {
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		if c == 'e' {
+			d.pos++
+			return nil
+		}
+		if c < '0' || c > '9' {
+			return d.syntaxError("dictionary key is not a string")
+		}
+		if key, err = d.dictKey(key, first); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Map {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(string(key)).Convert(v.Type().Key()), elem)
+			continue
+		}
+		f, ok := fields[string(key)]
+		if !ok {
+			if err := d.skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.value(fieldForSet(v, f.index)); err != nil {
+			return err
+		}
+	}
+}
+
+// fieldForSet returns the field at index, allocating nil embedded pointers.
+func fieldForSet(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// skip moves past the next value, checking its syntax.
+func (d *decoder) skip() error {
+	_, err := d.generic()
+	return err
+}
+
+func (d *decoder) generic() (interface{}, error) {
+	c, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c == 'i':
+		d.pos++
+		start := d.pos
+		digits, err := d.readInt('e')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			return nil, &SyntaxError{Offset: start, msg: "integer out of range"}
+		}
+		return n, nil
+	case c >= '0' && c <= '9':
+		s, err := d.readString()
+		if err != nil {
+			return nil, err
+		}
+		return string(s), nil
+	case c == 'l':
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		d.pos++
+		list := []interface{}{}
+		for {
+			c, err := d.peek()
+			if err != nil {
+				return nil, err
+			}
+			if c == 'e' {
+				d.pos++
+				return list, nil
+			}
+			elem, err := d.generic()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, elem)
+		}
+	case c == 'd':
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		d.pos++
+		dict := make(map[string]interface{})
+		var key []byte
+		for first := true; ; first = false {
+			c, err := d.peek()
+			if err != nil {
+				return nil, err
+			}
+			if c == 'e' {
+				d.pos++
+				return dict, nil
+			}
+			if c < '0' || c > '9' {
+				return nil, d.syntaxError("dictionary key is not a string")
+			}
+			if key, err = d.dictKey(key, first); err != nil {
+				return nil, err
+			}
+			if dict[string(key)], err = d.generic(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, d.syntaxError("invalid value")
+}
@@ -0,0 +1,67 @@
+package transmission
+
+import (
+	"context"
+	"reflect"
+)
+
+// BlocklistSettings are the blocklist fields of the session.
+type BlocklistSettings struct {
+	Enabled bool   `json:"blocklist-enabled"`
+	URL     string `json:"blocklist-url"`
+	// Size is the number of rules in the loaded blocklist
+	Size int `json:"blocklist-size"`
+}
+
+var blocklistFields = getJSONTags(reflect.TypeOf(BlocklistSettings{}))
+
+type getBlocklistSettingsResponse struct {
+	Result    string            `json:"result"`
+	Arguments BlocklistSettings `json:"arguments"`
+	Tag       int64             `json:"tag"`
+}
+
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md#45-blocklist
+type updateBlocklistResponse struct {
+	Result    string                      `json:"result"`
+	Arguments updateBlocklistResponseArgs `json:"arguments"`
+	Tag       int64                       `json:"tag"`
+}
+
+type updateBlocklistResponseArgs struct {
+	BlocklistSize int `json:"blocklist-size"`
+}
+
+func (t *Client) GetBlocklistSettings(ctx context.Context) (*BlocklistSettings, error) {
+	var response getBlocklistSettingsResponse
+	req := getSessionRequestArgs{
+		SessionID: t.getCurrentSessionID(),
+		Fields:    blocklistFields,
+	}
+	if err := t.callRPC(ctx, "session-get", &req, &response); err != nil {
+		return nil, err
+	}
+	return &response.Arguments, nil
+}
+
+// SetBlocklist enables or disables the blocklist and sets the URL it is
+// updated from. An empty url leaves the URL unchanged.
+func (t *Client) SetBlocklist(ctx context.Context, enabled bool, url string) error {
+	settings := map[string]interface{}{
+		"blocklist-enabled": enabled,
+	}
+	if url != "" {
+		settings["blocklist-url"] = url
+	}
+	return t.SetSession(ctx, settings)
+}
+
+// UpdateBlocklist makes the daemon download the blocklist from its URL, and
+// returns the number of rules loaded.
+func (t *Client) UpdateBlocklist(ctx context.Context) (int, error) {
+	var response updateBlocklistResponse
+	if err := t.callRPC(ctx, "blocklist-update", nil, &response); err != nil {
+		return 0, err
+	}
+	return response.Arguments.BlocklistSize, nil
+}
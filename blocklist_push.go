@@ -0,0 +1,142 @@
+package transmission
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// restoreTimeout bounds restoring the blocklist settings, which is done even
+// when the context passed to PushBlocklist is done.
+const restoreTimeout = 10 * time.Second
+
+// BlocklistRule blocks the addresses from First to Last, inclusive.
+type BlocklistRule struct {
+	Description string
+	First       net.IP
+	Last        net.IP
+}
+
+// String formats the rule as a line of a P2P format blocklist.
+func (r BlocklistRule) String() string {
+	return fmt.Sprintf("%s:%s-%s", r.Description, r.First, r.Last)
+}
+
+type pushBlocklistConfig struct {
+	listenAddr    string
+	advertiseHost string
+}
+
+type PushBlocklistOption func(*pushBlocklistConfig)
+
+// BlocklistListenAddrOption sets the address the blocklist is served on, by
+// default a random port on the local address used to reach the daemon, which
+// is loopback for a daemon on the same host. Use ":0" to serve on every
+// interface, when the daemon reaches this host through another address.
+func BlocklistListenAddrOption(addr string) PushBlocklistOption {
+	return func(c *pushBlocklistConfig) {
+		c.listenAddr = addr
+	}
+}
+
+// BlocklistAdvertiseHostOption sets the host the daemon downloads the
+// blocklist from, by default the local address used to reach the daemon.
+func BlocklistAdvertiseHostOption(host string) PushBlocklistOption {
+	return func(c *pushBlocklistConfig) {
+		c.advertiseHost = host
+	}
+}
+
+// PushBlocklist serves a P2P format blocklist on a short-lived HTTP listener,
+// points the daemon at it and updates the daemon's blocklist from it. The
+// previous blocklist settings, enabled or not and the URL, are restored
+// afterwards, so a disabled blocklist stays disabled. It returns the number of
+// rules the daemon loaded.
+func (t *Client) PushBlocklist(ctx context.Context, blocklist io.Reader, opts ...PushBlocklistOption) (int, error) {
+	var config pushBlocklistConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	data, err := ioutil.ReadAll(blocklist)
+	if err != nil {
+		return 0, fmt.Errorf("failed reading blocklist: %w", err)
+	}
+	if config.advertiseHost == "" || config.listenAddr == "" {
+		host, err := t.localHost()
+		if err != nil {
+			return 0, fmt.Errorf("failed finding the local address to reach the daemon: %w", err)
+		}
+		if config.advertiseHost == "" {
+			config.advertiseHost = host
+		}
+		if config.listenAddr == "" {
+			config.listenAddr = net.JoinHostPort(host, "0")
+		}
+	}
+	listener, err := net.Listen("tcp", config.listenAddr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to listen: %w", err)
+	}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write(data)
+		}),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	previous, err := t.GetBlocklistSettings(ctx)
+	if err != nil {
+		return 0, err
+	}
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	blocklistURL := "http://" + net.JoinHostPort(config.advertiseHost, port) + "/blocklist.p2p"
+	if err := t.SetBlocklist(ctx, true, blocklistURL); err != nil {
+		return 0, err
+	}
+	ruleCount, err := t.UpdateBlocklist(ctx)
+	// ctx may be done by now, the restore gets a context of its own
+	restoreCtx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+	defer cancel()
+	// SetBlocklist leaves an empty URL alone, the previous one is set as is
+	restoreErr := t.SetSession(restoreCtx, map[string]interface{}{
+		"blocklist-enabled": previous.Enabled,
+		"blocklist-url":     previous.URL,
+	})
+	if err == nil && restoreErr != nil {
+		err = fmt.Errorf("failed restoring blocklist settings: %w", restoreErr)
+	}
+	return ruleCount, err
+}
+
+// localHost returns the local address used to reach the daemon.
+func (t *Client) localHost() (string, error) {
+	if t.socketPath != "" {
+		return "127.0.0.1", nil
+	}
+	u, err := url.Parse(t.rootURL)
+	if err != nil {
+		return "", err
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	// Dialing UDP doesn't send anything, it only picks the route
+	conn, err := net.Dial("udp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
@@ -0,0 +1,93 @@
+package transmission_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+const testBlocklist = `# test rules
+first:10.0.0.0-10.0.0.255
+
+second:192.168.1.1-192.168.1.1
+`
+
+func pushTestBlocklist(t *testing.T, client *transmission.Client) int {
+	t.Helper()
+	ruleCount, err := client.PushBlocklist(context.Background(), strings.NewReader(testBlocklist),
+		transmission.BlocklistListenAddrOption("127.0.0.1:0"),
+		transmission.BlocklistAdvertiseHostOption("127.0.0.1"))
+	if err != nil {
+		t.Fatalf("PushBlocklist: %v", err)
+	}
+	return ruleCount
+}
+
+func TestPushBlocklistRestoresSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+	}{
+		{"disabled", map[string]interface{}{"blocklist-enabled": false, "blocklist-url": "http://example.com/list"}},
+		{"enabled", map[string]interface{}{"blocklist-enabled": true, "blocklist-url": "http://example.com/list"}},
+		{"empty URL", map[string]interface{}{"blocklist-enabled": false, "blocklist-url": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestClient(t)
+			server.SetSession(tt.settings)
+			if ruleCount := pushTestBlocklist(t, client); ruleCount != 2 {
+				t.Errorf("daemon loaded %d rules, want 2", ruleCount)
+			}
+			session := server.Session()
+			for key, want := range tt.settings {
+				if session[key] != want {
+					t.Errorf("%s is %v after the push, want %v", key, session[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPushBlocklistRestoresAfterCancel(t *testing.T) {
+	client, server := newTestClient(t)
+	server.SetSession(map[string]interface{}{"blocklist-enabled": false, "blocklist-url": "http://example.com/list"})
+	ctx, cancel := context.WithCancel(context.Background())
+	// The push fails once the update is cancelled, but the settings still
+	// have to be put back
+	server.SetLatency("blocklist-update", time.Second)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	_, err := client.PushBlocklist(ctx, strings.NewReader(testBlocklist),
+		transmission.BlocklistListenAddrOption("127.0.0.1:0"),
+		transmission.BlocklistAdvertiseHostOption("127.0.0.1"))
+	if err == nil {
+		t.Fatal("PushBlocklist succeeded after its context was cancelled")
+	}
+	if url := server.Session()["blocklist-url"]; url != "http://example.com/list" {
+		t.Errorf("blocklist-url is %v after a cancelled push", url)
+	}
+}
+
+func TestPushBlocklistListensOnRouteToDaemon(t *testing.T) {
+	client, server := newTestClient(t)
+	ruleCount, err := client.PushBlocklist(context.Background(), strings.NewReader(testBlocklist))
+	if err != nil || ruleCount != 2 {
+		t.Fatalf("PushBlocklist loaded %d rules, %v, want 2", ruleCount, err)
+	}
+	// The fake daemon is on loopback, so the blocklist is only served there
+	var urls []string
+	for _, request := range server.Requests() {
+		if arguments := string(request.Arguments); request.Method == "session-set" && strings.Contains(arguments, "/blocklist.p2p") {
+			urls = append(urls, arguments)
+		}
+	}
+	if len(urls) != 1 || !strings.Contains(urls[0], `"http://127.0.0.1:`) {
+		t.Errorf("the blocklist was served at %q, want loopback", urls)
+	}
+}
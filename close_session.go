@@ -0,0 +1,34 @@
+package transmission
+
+import (
+	"context"
+	"time"
+)
+
+// CloseSession shuts the daemon down gracefully, letting it save its resume
+// data first.
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md#43-closing-a-session
+func (t *Client) CloseSession(ctx context.Context) error {
+	var response genericResponse
+	return t.callRPC(ctx, "session-close", nil, &response)
+}
+
+// WaitForShutdown polls the RPC endpoint every interval until it stops
+// answering, or ctx is done.
+func (t *Client) WaitForShutdown(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := t.getSessionID(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
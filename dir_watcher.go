@@ -0,0 +1,370 @@
+package transmission
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Suffixes appended to the files a DirWatcher has processed.
+const (
+	AddedSuffix  = ".added"
+	FailedSuffix = ".failed"
+)
+
+// DirResult is the outcome of adding one file found by a DirWatcher.
+type DirResult struct {
+	Path string
+	// SubDir is the directory of the file relative to the watched directory,
+	// which the torrents were downloaded into
+	SubDir string
+	// IDs are the torrents added
+	IDs []int
+	// Links has the outcome of every magnet link of a .magnet or .txt file
+	Links []LinkResult
+	// Err is set if the file or any of its links failed
+	Err error
+	// Retry is set if the file, or some of its links, failed for a reason
+	// other than the torrent itself, like the daemon being unreachable. They
+	// are left in place and added again at the next scan
+	Retry bool
+}
+
+// LinkResult is the outcome of adding one magnet link of a file.
+type LinkResult struct {
+	Link string
+	ID   int
+	Err  error
+}
+
+// DirWatcher polls a directory tree for .torrent files, and .magnet or .txt
+// files holding one magnet link per line, and adds them. Files in
+// subdirectories are downloaded to the same subdirectory of the download
+// directory, through DownloadSubDirOption. Processed files are renamed with
+// AddedSuffix or FailedSuffix. When only some links of a file fail, the
+// added links go to a file with AddedSuffix and the failed ones to a file
+// with FailedSuffix, so the failed file can be fixed and dropped in again.
+// Only torrents the daemon refuses, or links that don't parse, are marked
+// failed: when the daemon can't be reached the file is left in place, holding
+// the links still to add, and retried at the next scan. .txt files without
+// magnet links are left alone.
+type DirWatcher struct {
+	client   TorrentWriter
+	dir      string
+	interval time.Duration
+	addOpts  []AddMagnetLinkOption
+	onResult func(DirResult)
+	// seen holds the size and modification time of files at the last scan, so
+	// files still being written are left for the next one
+	seen map[string]fileStamp
+	// ignored holds the .txt files without magnet links, which are read
+	// again once they change
+	ignored map[string]fileStamp
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+type DirWatcherOption func(*DirWatcher)
+
+// DirPollIntervalOption sets the time between scans, 5 seconds by default.
+func DirPollIntervalOption(interval time.Duration) DirWatcherOption {
+	return func(w *DirWatcher) {
+		w.interval = interval
+	}
+}
+
+// DirAddOption passes options to every add, like PausedOption or a
+// DownloadDirOption for the subdirectories to be relative to.
+func DirAddOption(opts ...AddMagnetLinkOption) DirWatcherOption {
+	return func(w *DirWatcher) {
+		w.addOpts = append(w.addOpts, opts...)
+	}
+}
+
+// DirResultOption is called with the outcome of every processed file.
+func DirResultOption(onResult func(DirResult)) DirWatcherOption {
+	return func(w *DirWatcher) {
+		w.onResult = onResult
+	}
+}
+
+func NewDirWatcher(client TorrentWriter, dir string, opts ...DirWatcherOption) *DirWatcher {
+	w := &DirWatcher{
+		client:   client,
+		dir:      dir,
+		interval: 5 * time.Second,
+		seen:     make(map[string]fileStamp),
+		ignored:  make(map[string]fileStamp),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Run scans the directory every interval until ctx is done, and returns the
+// context's error. A file is added once it is unchanged between two scans.
+func (w *DirWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Scan(ctx); err != nil && ctx.Err() == nil && w.onResult != nil {
+			w.onResult(DirResult{Path: w.dir, Err: err})
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan adds the files that haven't changed since the previous scan, and
+// returns their results.
+func (w *DirWatcher) Scan(ctx context.Context) ([]DirResult, error) {
+	var ready []string
+	present := make(map[string]bool)
+	err := filepath.Walk(w.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") && path != w.dir {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !watchedFile(path) {
+			return nil
+		}
+		present[path] = true
+		stamp := fileStamp{size: info.Size(), modTime: info.ModTime()}
+		if ignored, ok := w.ignored[path]; ok && ignored == stamp {
+			return nil
+		}
+		delete(w.ignored, path)
+		if previous, ok := w.seen[path]; ok && previous == stamp {
+			ready = append(ready, path)
+		}
+		w.seen[path] = stamp
+		return nil
+	})
+	for path := range w.seen {
+		if !present[path] {
+			delete(w.seen, path)
+		}
+	}
+	for path := range w.ignored {
+		if !present[path] {
+			delete(w.ignored, path)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var results []DirResult
+	for _, path := range ready {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		stamp := w.seen[path]
+		delete(w.seen, path)
+		result, ok := w.add(ctx, path)
+		if !ok {
+			w.ignored[path] = stamp
+			continue
+		}
+		if err := w.finish(path, result); err != nil && result.Err == nil {
+			result.Err = err
+		}
+		if result.Retry {
+			// A rewritten file changed, and is only added again once stable
+			w.seen[path] = stamp
+		}
+		results = append(results, result)
+		if w.onResult != nil {
+			w.onResult(result)
+		}
+	}
+	return results, nil
+}
+
+func watchedFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".torrent", ".magnet", ".txt":
+		return true
+	}
+	return false
+}
+
+// add adds the torrents of a file. It is false for .txt files without
+// magnet links, which are left alone.
+func (w *DirWatcher) add(ctx context.Context, path string) (DirResult, bool) {
+	result := DirResult{Path: path}
+	if subDir, err := filepath.Rel(w.dir, filepath.Dir(path)); err == nil && subDir != "." {
+		result.SubDir = filepath.ToSlash(subDir)
+	}
+	opts := append([]AddMagnetLinkOption{}, w.addOpts...)
+	if result.SubDir != "" {
+		opts = append(opts, DownloadSubDirOption(result.SubDir))
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		result.Err = err
+		return result, true
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".torrent" {
+		id, err := w.client.AddTorrentFile(ctx, data, opts...)
+		if err == nil {
+			result.IDs = append(result.IDs, id)
+		}
+		result.Err = err
+		result.Retry = retryable(err)
+		return result, true
+	}
+	links := magnetLinks(data)
+	if len(links) == 0 {
+		if ext == ".txt" {
+			return result, false
+		}
+		result.Err = errors.New("no magnet links in file")
+		return result, true
+	}
+	failed := 0
+	for _, link := range links {
+		id, err := w.client.AddMagnetLink(ctx, link, opts...)
+		result.Links = append(result.Links, LinkResult{Link: link, ID: id, Err: err})
+		if err != nil {
+			failed++
+			result.Retry = result.Retry || retryable(err)
+			continue
+		}
+		result.IDs = append(result.IDs, id)
+	}
+	if failed > 0 {
+		result.Err = fmt.Errorf("%d of %d magnet links failed", failed, len(links))
+	}
+	return result, true
+}
+
+// magnetLinks returns the lines of data that are magnet links.
+func magnetLinks(data []byte) []string {
+	var links []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		link := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(link, "magnet:") {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// retryable reports whether adding a torrent failed for a reason other than
+// the torrent itself, like a transport error or an HTTP error status, so it
+// may be added later. Errors reported by the daemon, and links that fail
+// before any call, aren't retryable.
+func retryable(err error) bool {
+	var rpcErr *RPCError
+	if err == nil || errors.As(err, &rpcErr) {
+		return false
+	}
+	var requestErr *RequestError
+	return errors.As(err, &requestErr)
+}
+
+// finish renames a processed file with AddedSuffix or FailedSuffix, or leaves
+// it in place to be retried. A file whose links partly failed is split into a
+// file of each instead, and keeps the links to retry. The links of a retried
+// file are appended to the files of its earlier scans.
+func (w *DirWatcher) finish(path string, result DirResult) error {
+	if result.Err == nil {
+		if _, err := os.Stat(path + AddedSuffix); err == nil && len(result.Links) > 0 {
+			if err := appendFile(path+AddedSuffix, magnetLinkLines(result.Links)); err != nil {
+				return err
+			}
+			return os.Remove(path)
+		}
+		return os.Rename(path, path+AddedSuffix)
+	}
+	var added, failed, retry bytes.Buffer
+	for _, link := range result.Links {
+		switch {
+		case link.Err == nil:
+			fmt.Fprintln(&added, link.Link)
+		case retryable(link.Err):
+			fmt.Fprintln(&retry, link.Link)
+		default:
+			fmt.Fprintln(&failed, link.Link)
+		}
+	}
+	if added.Len() == 0 && failed.Len() == 0 {
+		// A .torrent file, or a file whose links all failed the same way
+		if result.Retry {
+			return nil
+		}
+		return os.Rename(path, path+FailedSuffix)
+	}
+	if added.Len() == 0 && retry.Len() == 0 {
+		return os.Rename(path, path+FailedSuffix)
+	}
+	if added.Len() > 0 {
+		if err := appendFile(path+AddedSuffix, added.Bytes()); err != nil {
+			return err
+		}
+	}
+	if failed.Len() > 0 {
+		if err := appendFile(path+FailedSuffix, failed.Bytes()); err != nil {
+			return err
+		}
+	}
+	if retry.Len() > 0 {
+		return ioutil.WriteFile(path, retry.Bytes(), 0644)
+	}
+	return os.Remove(path)
+}
+
+func magnetLinkLines(links []LinkResult) []byte {
+	var lines bytes.Buffer
+	for _, link := range links {
+		fmt.Fprintln(&lines, link.Link)
+	}
+	return lines.Bytes()
+}
+
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (r DirResult) String() string {
+	if r.Err == nil {
+		return fmt.Sprintf("%s: added %v", r.Path, r.IDs)
+	}
+	retry := ""
+	if r.Retry {
+		retry = ", retrying at the next scan"
+	}
+	if len(r.IDs) == 0 {
+		return fmt.Sprintf("%s: %v%s", r.Path, r.Err, retry)
+	}
+	return fmt.Sprintf("%s: added %v, %v%s", r.Path, r.IDs, r.Err, retry)
+}
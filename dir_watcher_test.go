@@ -0,0 +1,183 @@
+package transmission_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+const (
+	linkA = "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567&dn=a"
+	linkB = "magnet:?xt=urn:btih:1123456789abcdef0123456789abcdef01234567&dn=b"
+	// badLink has no info hash, so AddMagnetLink refuses it
+	badLink = "magnet:?dn=bad"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// scanTwice scans until the files are unchanged between two scans, which is
+// when they are added.
+func scanTwice(t *testing.T, watcher *transmission.DirWatcher) []transmission.DirResult {
+	t.Helper()
+	var results []transmission.DirResult
+	for i := 0; i < 2; i++ {
+		scanned, err := watcher.Scan(context.Background())
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		results = append(results, scanned...)
+	}
+	return results
+}
+
+func TestDirWatcherSplitsPartlyFailedFiles(t *testing.T) {
+	client, server := newTestClient(t)
+	dir, err := ioutil.TempDir("", "dirwatcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "links.magnet")
+	writeFile(t, path, linkA+"\n"+badLink+"\n"+linkB+"\n")
+
+	results := scanTwice(t, transmission.NewDirWatcher(client, dir))
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	result := results[0]
+	if len(result.Links) != 3 || result.Links[1].Err == nil || result.Links[0].Err != nil || result.Links[2].Err != nil {
+		t.Errorf("got link results %+v, want only the second to fail", result.Links)
+	}
+	if len(result.IDs) != 2 || result.Err == nil {
+		t.Errorf("got IDs %v and error %v, want 2 torrents and an error", result.IDs, result.Err)
+	}
+	if len(server.Torrents()) != 2 {
+		t.Errorf("%d torrents added, want 2", len(server.Torrents()))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the original file is still there: %v", err)
+	}
+	if added := readFile(t, path+transmission.AddedSuffix); added != linkA+"\n"+linkB+"\n" {
+		t.Errorf("added file holds %q", added)
+	}
+	if failed := readFile(t, path+transmission.FailedSuffix); failed != badLink+"\n" {
+		t.Errorf("failed file holds %q", failed)
+	}
+}
+
+func TestDirWatcherLeavesTextFilesWithoutLinks(t *testing.T) {
+	client, server := newTestClient(t)
+	dir, err := ioutil.TempDir("", "dirwatcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	notes := filepath.Join(dir, "notes.txt")
+	writeFile(t, notes, "not a link\n")
+	links := filepath.Join(dir, "links.txt")
+	writeFile(t, links, "some text\n"+linkA+"\n")
+	empty := filepath.Join(dir, "empty.magnet")
+	writeFile(t, empty, "nothing here\n")
+
+	results := scanTwice(t, transmission.NewDirWatcher(client, dir))
+	if len(results) != 2 {
+		t.Fatalf("got results %v, want the .magnet and .txt with links", results)
+	}
+	if got := readFile(t, notes); got != "not a link\n" {
+		t.Errorf("notes.txt was changed to %q", got)
+	}
+	for _, path := range []string{links + transmission.AddedSuffix, empty + transmission.FailedSuffix} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s wasn't written: %v", filepath.Base(path), err)
+		}
+	}
+	if torrents := server.Torrents(); len(torrents) != 1 {
+		t.Errorf("%d torrents added, want 1", len(torrents))
+	}
+}
+
+func TestDirWatcherRetriesUnreachableDaemon(t *testing.T) {
+	client, server := newTestClient(t)
+	dir, err := ioutil.TempDir("", "dirwatcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "links.magnet")
+	writeFile(t, path, linkA+"\n"+badLink+"\n"+linkB+"\n")
+	watcher := transmission.NewDirWatcher(client, dir)
+
+	// The first link fails on the way to the daemon, the bad one is refused
+	server.FailHTTP("torrent-add", http.StatusServiceUnavailable, 1)
+	results := scanTwice(t, watcher)
+	if len(results) != 1 || !results[0].Retry {
+		t.Fatalf("got results %v, want one to retry", results)
+	}
+	if left := readFile(t, path); left != linkA+"\n" {
+		t.Errorf("the file left to retry holds %q", left)
+	}
+	if failed := readFile(t, path+transmission.FailedSuffix); failed != badLink+"\n" {
+		t.Errorf("failed file holds %q", failed)
+	}
+
+	// The rewritten file is added once unchanged between two scans
+	results = scanTwice(t, watcher)
+	if len(results) != 1 || results[0].Err != nil || results[0].Retry {
+		t.Fatalf("got results %v, want the retried link added", results)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the retried file is still there: %v", err)
+	}
+	if added := readFile(t, path+transmission.AddedSuffix); added != linkB+"\n"+linkA+"\n" {
+		t.Errorf("added file holds %q", added)
+	}
+	if len(server.Torrents()) != 2 {
+		t.Errorf("%d torrents added, want 2", len(server.Torrents()))
+	}
+}
+
+func TestDirWatcherLeavesTorrentFilesToRetry(t *testing.T) {
+	client, server := newTestClient(t)
+	dir, err := ioutil.TempDir("", "dirwatcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "file.torrent")
+	writeFile(t, path, "not bencoded")
+	watcher := transmission.NewDirWatcher(client, dir)
+
+	server.FailHTTP("torrent-add", http.StatusServiceUnavailable, 1)
+	if results := scanTwice(t, watcher); len(results) != 1 || !results[0].Retry {
+		t.Fatalf("got results %v, want one to retry", results)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("the file wasn't left in place: %v", err)
+	}
+	// Once the daemon answers it refuses the file, which is marked failed
+	if results := scanTwice(t, watcher); len(results) != 1 || results[0].Retry {
+		t.Fatalf("got results %v, want the file refused", results)
+	}
+	if _, err := os.Stat(path + transmission.FailedSuffix); err != nil {
+		t.Errorf("the refused file wasn't marked failed: %v", err)
+	}
+}
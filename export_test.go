@@ -0,0 +1,11 @@
+package transmission
+
+// Exported for the table tests of the JSON-RPC name conversions.
+var (
+	SnakeCase  = snakeCase
+	SnakeKeys  = snakeKeys
+	LegacyKeys = legacyKeys
+)
+
+// Exported for the tests of table format decoding.
+type TorrentList = torrentList
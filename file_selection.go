@@ -0,0 +1,238 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type fileMatcher func(name string) (bool, error)
+
+type fileRules struct {
+	include    []fileMatcher
+	exclude    []fileMatcher
+	minSize    int64
+	maxSize    int64
+	priorities []filePriority
+}
+
+type filePriority struct {
+	match    fileMatcher
+	priority int
+}
+
+// FileRule decides which files of a torrent are downloaded, see ResolveFiles.
+type FileRule func(*fileRules)
+
+// globMatcher matches a case insensitive glob against the base name of a file,
+// or against its full path within the torrent if the pattern contains a slash.
+func globMatcher(pattern string) fileMatcher {
+	pattern = strings.ToLower(pattern)
+	return func(name string) (bool, error) {
+		name = strings.ToLower(name)
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(name)
+		}
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return matched, nil
+	}
+}
+
+func regexpMatcher(expr string) fileMatcher {
+	re, err := regexp.Compile(expr)
+	return func(name string) (bool, error) {
+		if err != nil {
+			return false, fmt.Errorf("invalid regular expression %q: %w", expr, err)
+		}
+		return re.MatchString(name), nil
+	}
+}
+
+// Include downloads only the files matching one of the Include or
+// IncludeRegexp rules, see globMatcher.
+func Include(pattern string) FileRule {
+	return func(r *fileRules) {
+		r.include = append(r.include, globMatcher(pattern))
+	}
+}
+
+// Exclude skips the files matching the glob.
+func Exclude(pattern string) FileRule {
+	return func(r *fileRules) {
+		r.exclude = append(r.exclude, globMatcher(pattern))
+	}
+}
+
+// IncludeRegexp is Include for a regular expression matched against the full
+// path of the file within the torrent.
+func IncludeRegexp(expr string) FileRule {
+	return func(r *fileRules) {
+		r.include = append(r.include, regexpMatcher(expr))
+	}
+}
+
+// ExcludeRegexp is Exclude for a regular expression matched against the full
+// path of the file within the torrent.
+func ExcludeRegexp(expr string) FileRule {
+	return func(r *fileRules) {
+		r.exclude = append(r.exclude, regexpMatcher(expr))
+	}
+}
+
+// MinSize skips files smaller than size bytes.
+func MinSize(size int64) FileRule {
+	return func(r *fileRules) {
+		r.minSize = size
+	}
+}
+
+// MaxSize skips files larger than size bytes.
+func MaxSize(size int64) FileRule {
+	return func(r *fileRules) {
+		r.maxSize = size
+	}
+}
+
+// Prioritize sets the priority of the wanted files matching the glob to
+// PriorityLow, PriorityNormal or PriorityHigh. Later rules win.
+func Prioritize(pattern string, priority int) FileRule {
+	return func(r *fileRules) {
+		r.priorities = append(r.priorities, filePriority{
+			match:    globMatcher(pattern),
+			priority: priority,
+		})
+	}
+}
+
+// FileSelection lists file indices of Torrent.Files.
+type FileSelection struct {
+	Wanted   []int `json:"wanted"`
+	Unwanted []int `json:"unwanted"`
+	// Priorities holds the files matched by a Prioritize rule, by priority
+	Priorities map[int][]int `json:"priorities,omitempty"`
+}
+
+func matchAny(matchers []fileMatcher, name string) (bool, error) {
+	for _, match := range matchers {
+		matched, err := match(name)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+// ResolveFiles applies the rules to the files of a torrent. A file is wanted
+// if it matches an include rule, or there are none, matches no exclude rule
+// and is within the size limits.
+func ResolveFiles(files []TorrentFile, rules ...FileRule) (*FileSelection, error) {
+	var r fileRules
+	for _, rule := range rules {
+		rule(&r)
+	}
+	selection := &FileSelection{
+		Wanted:   []int{},
+		Unwanted: []int{},
+	}
+	for i, file := range files {
+		wanted := len(r.include) == 0
+		if !wanted {
+			matched, err := matchAny(r.include, file.Name)
+			if err != nil {
+				return nil, err
+			}
+			wanted = matched
+		}
+		if wanted {
+			excluded, err := matchAny(r.exclude, file.Name)
+			if err != nil {
+				return nil, err
+			}
+			wanted = !excluded
+		}
+		if r.minSize > 0 && int64(file.Length) < r.minSize {
+			wanted = false
+		}
+		if r.maxSize > 0 && int64(file.Length) > r.maxSize {
+			wanted = false
+		}
+		if !wanted {
+			selection.Unwanted = append(selection.Unwanted, i)
+			continue
+		}
+		selection.Wanted = append(selection.Wanted, i)
+		priority, prioritized := 0, false
+		for _, p := range r.priorities {
+			matched, err := p.match(file.Name)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				priority, prioritized = p.priority, true
+			}
+		}
+		if prioritized {
+			if selection.Priorities == nil {
+				selection.Priorities = make(map[int][]int)
+			}
+			selection.Priorities[priority] = append(selection.Priorities[priority], i)
+		}
+	}
+	return selection, nil
+}
+
+// Options returns the torrent-set options applying the selection. Empty lists
+// are left out, as the daemon would apply them to every file.
+func (s *FileSelection) Options() []SetTorrentsOption {
+	var opts []SetTorrentsOption
+	if len(s.Wanted) > 0 {
+		opts = append(opts, FilesWantedOption(s.Wanted...))
+	}
+	if len(s.Unwanted) > 0 {
+		opts = append(opts, FilesUnwantedOption(s.Unwanted...))
+	}
+	priorities := make([]int, 0, len(s.Priorities))
+	for priority := range s.Priorities {
+		priorities = append(priorities, priority)
+	}
+	sort.Ints(priorities)
+	for _, priority := range priorities {
+		opts = append(opts, PriorityOption(priority, s.Priorities[priority]...))
+	}
+	return opts
+}
+
+// SelectFiles resolves the rules against the files of a torrent and applies
+// the result, see ResolveFiles.
+func (t *Client) SelectFiles(ctx context.Context, id TorrentID, rules ...FileRule) (*FileSelection, error) {
+	var files []TorrentFile
+	found := false
+	err := t.IterateTorrents(ctx, func(torrent Torrent) error {
+		files, found = torrent.Files, true
+		return nil
+	}, TorrentIDsOption(id), TorrentFieldsOption("id", "files"))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("torrent %s not found", id)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("torrent has no files, its metadata may not be downloaded yet")
+	}
+	selection, err := ResolveFiles(files, rules...)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.SetTorrents(ctx, []TorrentID{id}, selection.Options()...); err != nil {
+		return nil, err
+	}
+	return selection, nil
+}
@@ -0,0 +1,278 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FleetMember is one server of a Fleet.
+type FleetMember struct {
+	Name string
+	// Labels are matched against torrent labels by the LabelAffinity policy
+	Labels []string
+	Client API
+}
+
+// Fleet spreads calls over several servers. Torrent IDs are per server, so
+// select torrents by hash when calling every member.
+type Fleet struct {
+	members []FleetMember
+	policy  AddPolicy
+}
+
+type FleetOption func(*Fleet)
+
+// AddPolicyOption sets how AddMagnetLink picks a server, LeastLoaded by
+// default.
+func AddPolicyOption(policy AddPolicy) FleetOption {
+	return func(f *Fleet) {
+		f.policy = policy
+	}
+}
+
+func NewFleet(members []FleetMember, opts ...FleetOption) *Fleet {
+	f := &Fleet{
+		members: members,
+		policy:  LeastLoaded,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Members returns the servers of the fleet.
+func (f *Fleet) Members() []FleetMember {
+	return append([]FleetMember{}, f.members...)
+}
+
+// Member returns the server with the given name.
+func (f *Fleet) Member(name string) (FleetMember, bool) {
+	for _, member := range f.members {
+		if member.Name == name {
+			return member, true
+		}
+	}
+	return FleetMember{}, false
+}
+
+// ServerError is the failure of one server of a Fleet.
+type ServerError struct {
+	Server string
+	Err    error
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server %s: %v", e.Server, e.Err)
+}
+
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// FleetError lists the servers that failed during a fleet call. The results
+// of the other servers are still returned alongside it.
+type FleetError struct {
+	Errors []*ServerError
+	// Servers is the number of servers called
+	Servers int
+}
+
+func (e *FleetError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d of %d servers failed: %s", len(e.Errors), e.Servers, strings.Join(messages, "; "))
+}
+
+// All is true if no server succeeded.
+func (e *FleetError) All() bool {
+	return len(e.Errors) == e.Servers
+}
+
+// each calls fn for every member concurrently, and collects the errors in a
+// *FleetError.
+func (f *Fleet) each(fn func(i int, member FleetMember) error) error {
+	errs := make([]error, len(f.members))
+	var wg sync.WaitGroup
+	for i, member := range f.members {
+		wg.Add(1)
+		go func(i int, member FleetMember) {
+			defer wg.Done()
+			errs[i] = fn(i, member)
+		}(i, member)
+	}
+	wg.Wait()
+	fleetErr := &FleetError{Servers: len(f.members)}
+	for i, err := range errs {
+		if err != nil {
+			fleetErr.Errors = append(fleetErr.Errors, &ServerError{Server: f.members[i].Name, Err: err})
+		}
+	}
+	if len(fleetErr.Errors) == 0 {
+		return nil
+	}
+	return fleetErr
+}
+
+// ServerTorrent is a torrent and the server it is on.
+type ServerTorrent struct {
+	Server string `json:"server"`
+	Torrent
+}
+
+// GetTorrents gets the torrents of every server concurrently. If some servers
+// fail the torrents of the others are returned with a *FleetError.
+func (f *Fleet) GetTorrents(ctx context.Context, ids ...TorrentID) ([]ServerTorrent, error) {
+	results := make([][]Torrent, len(f.members))
+	err := f.each(func(i int, member FleetMember) error {
+		torrents, err := member.Client.GetTorrents(ctx, ids...)
+		results[i] = torrents
+		return err
+	})
+	var torrents []ServerTorrent
+	for i, result := range results {
+		for _, torrent := range result {
+			torrents = append(torrents, ServerTorrent{Server: f.members[i].Name, Torrent: torrent})
+		}
+	}
+	return torrents, err
+}
+
+// ServerStats are the session statistics of one server.
+type ServerStats struct {
+	Server string   `json:"server"`
+	Stats  *Session `json:"stats"`
+}
+
+// GetSessionStats gets the statistics of every server concurrently, in member
+// order. Failed servers are left out and reported with a *FleetError.
+func (f *Fleet) GetSessionStats(ctx context.Context) ([]ServerStats, error) {
+	results := make([]*Session, len(f.members))
+	err := f.each(func(i int, member FleetMember) error {
+		stats, err := member.Client.GetSessionStats(ctx)
+		results[i] = stats
+		return err
+	})
+	var stats []ServerStats
+	for i, result := range results {
+		if result != nil {
+			stats = append(stats, ServerStats{Server: f.members[i].Name, Stats: result})
+		}
+	}
+	return stats, err
+}
+
+// AddMagnetLink adds the link to the server picked by the add policy, and
+// returns the server's name with the torrent's ID.
+func (f *Fleet) AddMagnetLink(ctx context.Context, link string, opts ...AddMagnetLinkOption) (string, int, error) {
+	var req addTransmissionRequestArgs
+	for _, opt := range opts {
+		opt(&req)
+	}
+	member, err := f.policy(ctx, f.members, req.Labels)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to pick a server: %w", err)
+	}
+	id, err := member.Client.AddMagnetLink(ctx, link, opts...)
+	if err != nil {
+		return member.Name, 0, &ServerError{Server: member.Name, Err: err}
+	}
+	return member.Name, id, nil
+}
+
+// AddPolicy picks the server a torrent with the given labels is added to.
+type AddPolicy func(ctx context.Context, members []FleetMember, labels []string) (FleetMember, error)
+
+var errNoServers = errors.New("no server available")
+
+// LeastLoaded picks the server with the fewest active torrents, skipping
+// servers that can't be reached.
+func LeastLoaded(ctx context.Context, members []FleetMember, labels []string) (FleetMember, error) {
+	fleet := NewFleet(members)
+	stats, err := fleet.GetSessionStats(ctx)
+	if len(stats) == 0 {
+		return FleetMember{}, firstError(err, errNoServers)
+	}
+	best := stats[0]
+	for _, s := range stats[1:] {
+		if s.Stats.ActiveTorrentCount < best.Stats.ActiveTorrentCount ||
+			s.Stats.ActiveTorrentCount == best.Stats.ActiveTorrentCount && s.Stats.TorrentCount < best.Stats.TorrentCount {
+			best = s
+		}
+	}
+	member, _ := fleet.Member(best.Server)
+	return member, nil
+}
+
+// MostFreeSpace picks the server with the most free space in its download
+// directory, skipping servers that can't be reached.
+func MostFreeSpace(ctx context.Context, members []FleetMember, labels []string) (FleetMember, error) {
+	free := make([]int64, len(members))
+	err := NewFleet(members).each(func(i int, member FleetMember) error {
+		free[i] = -1
+		session, err := member.Client.GetSession(ctx)
+		if err != nil {
+			return err
+		}
+		downloadDir, _ := session["download-dir"].(string)
+		freeBytes, _, err := member.Client.FreeSpace(ctx, downloadDir)
+		if err != nil {
+			return err
+		}
+		free[i] = freeBytes
+		return nil
+	})
+	best := -1
+	for i := range members {
+		if free[i] >= 0 && (best < 0 || free[i] > free[best]) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return FleetMember{}, firstError(err, errNoServers)
+	}
+	return members[best], nil
+}
+
+// LabelAffinity picks among the servers sharing a label with the torrent
+// using fallback, or among every server if none does or the torrent has no
+// labels.
+func LabelAffinity(fallback AddPolicy) AddPolicy {
+	return func(ctx context.Context, members []FleetMember, labels []string) (FleetMember, error) {
+		var matching []FleetMember
+		for _, member := range members {
+			if sharesLabel(member.Labels, labels) {
+				matching = append(matching, member)
+			}
+		}
+		if len(matching) == 0 {
+			matching = members
+		}
+		return fallback(ctx, matching, labels)
+	}
+}
+
+func sharesLabel(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
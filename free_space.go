@@ -0,0 +1,35 @@
+package transmission
+
+import (
+	"context"
+)
+
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md#47-free-space
+type freeSpaceRequestArgs struct {
+	Path string `json:"path"`
+}
+
+type freeSpaceResponse struct {
+	Result    string                `json:"result"`
+	Arguments freeSpaceResponseArgs `json:"arguments"`
+	Tag       int64                 `json:"tag"`
+}
+
+type freeSpaceResponseArgs struct {
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"size-bytes"`
+	TotalSize int64  `json:"total_size"`
+}
+
+// FreeSpace returns the free and total bytes of the filesystem holding path,
+// as seen by the daemon. Daemons before 4.0 don't report the total size.
+func (t *Client) FreeSpace(ctx context.Context, path string) (freeBytes, totalBytes int64, err error) {
+	var response freeSpaceResponse
+	req := freeSpaceRequestArgs{
+		Path: path,
+	}
+	if err := t.callRPC(ctx, "free-space", &req, &response); err != nil {
+		return 0, 0, err
+	}
+	return response.Arguments.SizeBytes, response.Arguments.TotalSize, nil
+}
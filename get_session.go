@@ -2,7 +2,6 @@ package transmission
 
 import (
 	"context"
-	"fmt"
 )
 
 type getSessionRequestArgs struct {
@@ -18,13 +17,10 @@ type getSessionResponse struct {
 func (t *Client) GetSession(ctx context.Context) (map[string]interface{}, error) {
 	var response getSessionResponse
 	req := getSessionRequestArgs{
-		SessionID: t.sessionID,
+		SessionID: t.getCurrentSessionID(),
 	}
 	if err := t.callRPC(ctx, "session-get", &req, &response); err != nil {
 		return nil, err
 	}
-	if response.Result != "success" {
-		return nil, fmt.Errorf("failed to get session stats: %s", response.Result)
-	}
 	return response.Arguments, nil
 }
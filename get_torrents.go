@@ -2,7 +2,6 @@ package transmission
 
 import (
 	"context"
-	"fmt"
 	"reflect"
 	"strings"
 )
@@ -125,8 +124,10 @@ type TorrentStatistics struct {
 	  As a result, only paused torrents can be finished. */
 	IsFinished bool `json:"isFinished"`
 	/** True if the torrent is running, but has been idle for long enough
-	  to be considered stalled.  @see tr_sessionGetQueueStalledMinutes() */
-	IsStalled int `json:"IsStalled"`
+	  to be considered stalled.  @see tr_sessionGetQueueStalledMinutes()
+	  This used to be an int requested as IsStalled, a field the daemon
+	  doesn't know, so it was always 0. */
+	IsStalled bool `json:"isStalled"`
 	/** Byte count of how much data is left to be downloaded until we've got
 	  all the pieces that we want. [0...tr_stat.sizeWhenDone] */
 	LeftUntilDone uint64 `json:"leftUntilDone"`
@@ -250,21 +251,24 @@ type Torrent struct {
 }
 
 type listTorrentsRequestArgs struct {
-	IDs    []int    `json:"ids,omitempty"`
-	Fields []string `json:"fields"`
+	IDs    torrentIDs `json:"ids,omitempty"`
+	Fields []string   `json:"fields"`
+	Format string     `json:"format,omitempty"`
 }
 
 type listTorrentsResponse struct {
 	Result    string                   `json:"result"`
 	Arguments listTorrentsResponseArgs `json:"arguments"`
-	Tag       string                   `json:"tag"`
+	Tag       int64                    `json:"tag"`
 }
 
 type listTorrentsResponseArgs struct {
-	Torrents []Torrent `json:"torrents"`
+	Torrents torrentList `json:"torrents"`
+	// Removed is only set when requesting recently active torrents
+	Removed []int `json:"removed"`
 }
 
-func (t *Client) GetTorrents(ctx context.Context, ids ...int) ([]Torrent, error) {
+func (t *Client) GetTorrents(ctx context.Context, ids ...TorrentID) ([]Torrent, error) {
 	var response listTorrentsResponse
 	req := listTorrentsRequestArgs{
 		Fields: torrentFields,
@@ -272,11 +276,20 @@ func (t *Client) GetTorrents(ctx context.Context, ids ...int) ([]Torrent, error)
 	if ids != nil {
 		req.IDs = ids
 	}
+	if t.tableFormat {
+		req.Format = tableFormat
+	}
 	if err := t.callRPC(ctx, "torrent-get", &req, &response); err != nil {
 		return nil, err
 	}
-	if response.Result != "success" {
-		return nil, fmt.Errorf("failed to get session stats: %s", response.Result)
+	return []Torrent(response.Arguments.Torrents), nil
+}
+
+// GetTorrentsByID gets torrents by their numeric IDs, as GetTorrents did
+// before it took TorrentIDs. Without IDs it gets every torrent.
+func (t *Client) GetTorrentsByID(ctx context.Context, ids ...int) ([]Torrent, error) {
+	if len(ids) == 0 {
+		return t.GetTorrents(ctx)
 	}
-	return response.Arguments.Torrents, nil
+	return t.GetTorrents(ctx, IDs(ids...)...)
 }
@@ -0,0 +1,344 @@
+// Package fixture records RPC calls into golden files and replays them. It is
+// exposed through transmissiontest, and kept apart so transmission-util can
+// record without linking the fake daemon.
+package fixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Redacted replaces the values of redacted fields in fixtures.
+const Redacted = "REDACTED"
+
+// replaySessionID is the session ID a Replayer hands out.
+const replaySessionID = Redacted
+
+// Fixture is a recorded sequence of RPC calls, stored as JSON in golden files.
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one RPC call and the daemon's response to it. Headers aren't
+// recorded, so session IDs and Authorization headers never reach the file.
+type Interaction struct {
+	Method     string          `json:"method"`
+	Request    json.RawMessage `json:"request"`
+	StatusCode int             `json:"statusCode"`
+	// Response is the response body, or empty if it wasn't JSON
+	Response json.RawMessage `json:"response,omitempty"`
+	// ResponseText is the response body if it wasn't JSON
+	ResponseText string `json:"responseText,omitempty"`
+}
+
+// LoadFixture reads a golden file written by a Recorder.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// Save writes the fixture to a golden file.
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Recorder is an http.RoundTripper that records the RPC calls a client makes
+// to a real daemon. Pass it to transmission.TransportOption, or its Wrap
+// method to transmission.WrapTransportOption to record through the transport
+// the client would use anyway, and Save the fixture once done.
+type Recorder struct {
+	next   http.RoundTripper
+	redact map[string]bool
+	mu     sync.Mutex
+	calls  []Interaction
+}
+
+type RecorderOption func(*Recorder)
+
+// RedactFieldsOption redacts more fields from requests and responses, like
+// download-dir to keep paths out of fixtures. The session-id field is always
+// redacted.
+func RedactFieldsOption(fields ...string) RecorderOption {
+	return func(r *Recorder) {
+		for _, field := range fields {
+			r.redact[field] = true
+		}
+	}
+}
+
+// NewRecorder records the calls sent through next, or http.DefaultTransport if
+// next is nil.
+func NewRecorder(next http.RoundTripper, opts ...RecorderOption) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{
+		next:   next,
+		redact: map[string]bool{"session-id": true, "session_id": true},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.roundTrip(r.next, req)
+}
+
+// Wrap returns a transport recording into r the calls sent through next. A
+// Recorder can wrap the transports of several clients, which record into the
+// same fixture.
+func (r *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	return recordingTransport{recorder: r, next: next}
+}
+
+type recordingTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+func (t recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.recorder.roundTrip(t.next, req)
+}
+
+func (r *Recorder) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost || req.Body == nil {
+		return next.RoundTrip(req)
+	}
+	requestBody, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	// The session ID handshake isn't part of the conversation, a Replayer
+	// answers it by itself
+	if resp.StatusCode == http.StatusConflict {
+		return resp, nil
+	}
+	responseBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+
+	var envelope struct {
+		Method string `json:"method"`
+	}
+	json.Unmarshal(requestBody, &envelope)
+	call := Interaction{
+		Method:     envelope.Method,
+		Request:    r.redactJSON(requestBody),
+		StatusCode: resp.StatusCode,
+	}
+	if json.Valid(responseBody) {
+		call.Response = r.redactJSON(responseBody)
+	} else {
+		call.ResponseText = string(responseBody)
+	}
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Fixture returns the calls recorded so far.
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Fixture{Interactions: append([]Interaction{}, r.calls...)}
+}
+
+// Save writes the calls recorded so far to a golden file.
+func (r *Recorder) Save(path string) error {
+	return r.Fixture().Save(path)
+}
+
+func (r *Recorder) redactJSON(data []byte) json.RawMessage {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return json.RawMessage(data)
+	}
+	redacted, err := json.Marshal(r.redactValue(value))
+	if err != nil {
+		return json.RawMessage(data)
+	}
+	return redacted
+}
+
+func (r *Recorder) redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, v := range value {
+			if r.redact[key] {
+				value[key] = Redacted
+			} else {
+				value[key] = r.redactValue(v)
+			}
+		}
+	case []interface{}:
+		r.redactTable(value)
+		for i, v := range value {
+			value[i] = r.redactValue(v)
+		}
+	}
+	return value
+}
+
+// redactTable redacts the columns of redacted fields in a table format
+// torrent list, whose first row holds the field names.
+func (r *Recorder) redactTable(rows []interface{}) {
+	if len(rows) == 0 {
+		return
+	}
+	header, ok := rows[0].([]interface{})
+	if !ok {
+		return
+	}
+	for column, name := range header {
+		name, ok := name.(string)
+		if !ok {
+			return
+		}
+		if !r.redact[name] {
+			continue
+		}
+		for _, row := range rows[1:] {
+			if row, ok := row.([]interface{}); ok && column < len(row) {
+				row[column] = Redacted
+			}
+		}
+	}
+}
+
+// Replayer is an http.RoundTripper that answers a client's calls from a
+// fixture. Each call gets the first unused response recorded for its method,
+// with the tag or id of the call. It answers the session ID handshake itself.
+type Replayer struct {
+	fixture *Fixture
+	mu      sync.Mutex
+	used    []bool
+}
+
+func NewReplayer(fixture *Fixture) *Replayer {
+	return &Replayer{
+		fixture: fixture,
+		used:    make([]bool, len(fixture.Interactions)),
+	}
+}
+
+// LoadReplayer replays a golden file written by a Recorder.
+func LoadReplayer(path string) (*Replayer, error) {
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(fixture), nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+	if req.Method != http.MethodPost || req.Header.Get("X-Transmission-Session-Id") != replaySessionID {
+		return newResponse(req, http.StatusConflict, nil), nil
+	}
+	requestBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal(requestBody, &request); err != nil {
+		return nil, fmt.Errorf("transmissiontest: failed to decode request: %w", err)
+	}
+	var method string
+	json.Unmarshal(request["method"], &method)
+	call, ok := r.take(method)
+	if !ok {
+		return nil, fmt.Errorf("transmissiontest: no recorded response left for %q", method)
+	}
+	if call.Response == nil {
+		return newResponse(req, call.StatusCode, []byte(call.ResponseText)), nil
+	}
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(call.Response, &response); err != nil {
+		return newResponse(req, call.StatusCode, call.Response), nil
+	}
+	for _, key := range []string{"tag", "id"} {
+		if _, recorded := response[key]; recorded {
+			if value, ok := request[key]; ok {
+				response[key] = value
+			}
+		}
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(req, call.StatusCode, body), nil
+}
+
+// Unused returns the recorded calls that haven't been replayed.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for i, call := range r.fixture.Interactions {
+		if !r.used[i] {
+			unused = append(unused, call)
+		}
+	}
+	return unused
+}
+
+func (r *Replayer) take(method string) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, call := range r.fixture.Interactions {
+		if !r.used[i] && call.Method == method {
+			r.used[i] = true
+			return call, true
+		}
+	}
+	return Interaction{}, false
+}
+
+func newResponse(req *http.Request, statusCode int, body []byte) *http.Response {
+	header := make(http.Header)
+	header.Set("X-Transmission-Session-Id", replaySessionID)
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
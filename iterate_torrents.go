@@ -0,0 +1,98 @@
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type TorrentsOption func(*listTorrentsRequestArgs)
+
+// TorrentIDsOption limits the request to the given torrents.
+func TorrentIDsOption(ids ...TorrentID) TorrentsOption {
+	return func(req *listTorrentsRequestArgs) {
+		req.IDs = ids
+	}
+}
+
+// TorrentFieldsOption only requests the given fields, named as in the json
+// tags of Torrent. Every field is requested by default.
+func TorrentFieldsOption(fields ...string) TorrentsOption {
+	return func(req *listTorrentsRequestArgs) {
+		req.Fields = fields
+	}
+}
+
+// IterateTorrents calls fn for every torrent as it is decoded from the
+// response, so the whole list is never held in memory. Iteration stops at
+// the first error returned by fn, which is returned as is.
+//
+// Transmission sends the arguments before the result and tag, so fn is called
+// before the response is known to have succeeded. A failed result or a tag
+// mismatch is only returned once every torrent has been passed to fn.
+func (t *Client) IterateTorrents(ctx context.Context, fn func(Torrent) error, opts ...TorrentsOption) error {
+	req := listTorrentsRequestArgs{
+		Fields: torrentFields,
+	}
+	if t.tableFormat {
+		req.Format = tableFormat
+	}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	var fnErr error
+	err := t.streamRPC(ctx, "torrent-get", &req, func(decoder *json.Decoder) error {
+		if ok, err := expectObject(decoder); !ok {
+			return err
+		}
+		for decoder.More() {
+			key, err := decodeKey(decoder)
+			if err != nil {
+				return err
+			}
+			if key != "torrents" {
+				if err := skipValue(decoder); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := expectDelim(decoder, '['); err != nil {
+				return err
+			}
+			var header []string
+			for decoder.More() {
+				var element json.RawMessage
+				if err := decoder.Decode(&element); err != nil {
+					return err
+				}
+				// In table format the first element holds the field names
+				if header == nil && isJSONArray(element) {
+					if err := json.Unmarshal(element, &header); err != nil {
+						return err
+					}
+					continue
+				}
+				if header != nil {
+					var err error
+					if element, err = tableRowObject(header, element); err != nil {
+						return err
+					}
+				}
+				var torrent Torrent
+				if err := t.unmarshalValue(element, &torrent); err != nil {
+					return err
+				}
+				if fnErr = fn(torrent); fnErr != nil {
+					return fnErr
+				}
+			}
+			if err := expectDelim(decoder, ']'); err != nil {
+				return err
+			}
+		}
+		return expectDelim(decoder, '}')
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
@@ -0,0 +1,232 @@
+package transmission_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/transmissiontest"
+)
+
+// addTorrents fills the server with n torrents of a few files each.
+func addTorrents(server *transmissiontest.Server, n int) {
+	for i := 0; i < n; i++ {
+		var torrent transmission.Torrent
+		torrent.Name = fmt.Sprintf("torrent %d", i)
+		for j := 0; j < 4; j++ {
+			torrent.Files = append(torrent.Files, transmission.TorrentFile{
+				Name:   fmt.Sprintf("torrent %d/file %d.mkv", i, j),
+				Length: 1 << 30,
+			})
+		}
+		server.AddTorrent(torrent)
+	}
+}
+
+func TestIterateTorrents(t *testing.T) {
+	client, server := newTestClient(t)
+	addTorrents(server, 10)
+	var names []string
+	err := client.IterateTorrents(context.Background(), func(torrent transmission.Torrent) error {
+		names = append(names, torrent.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateTorrents: %v", err)
+	}
+	if len(names) != 10 || names[0] != "torrent 0" || names[9] != "torrent 9" {
+		t.Errorf("got names %q", names)
+	}
+}
+
+func TestIterateTorrentsStopsEarly(t *testing.T) {
+	client, server := newTestClient(t)
+	addTorrents(server, 100)
+	errStop := errors.New("stop")
+	calls := 0
+	err := client.IterateTorrents(context.Background(), func(transmission.Torrent) error {
+		calls++
+		if calls == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Errorf("got error %v, want the callback's error", err)
+	}
+	if calls != 3 {
+		t.Errorf("callback called %d times after returning an error at 3", calls)
+	}
+}
+
+func TestIterateTorrentsFailedResult(t *testing.T) {
+	client, server := newTestClient(t)
+	addTorrents(server, 2)
+	server.FailMethod("torrent-get", "no such method", 1)
+	err := client.IterateTorrents(context.Background(), func(transmission.Torrent) error {
+		return nil
+	})
+	var rpcErr *transmission.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Message != "no such method" {
+		t.Errorf("got error %v, want the failed result", err)
+	}
+}
+
+// benchmarkTorrents is the size of the library the benchmarks list.
+const benchmarkTorrents = 5000
+
+// cannedTransport answers torrent-get with a response the fake server encoded
+// once, so the benchmarks measure the client's decoding rather than the
+// server's encoding. Other calls go through to the server.
+type cannedTransport struct {
+	next http.RoundTripper
+	// prefix is the response up to its tag, which is added per request
+	prefix []byte
+}
+
+func (c *cannedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost {
+		return c.next.RoundTrip(req)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var request struct {
+		Method string `json:"method"`
+		Tag    int64  `json:"tag"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, err
+	}
+	forward := req.Clone(req.Context())
+	forward.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if request.Method != "torrent-get" {
+		return c.next.RoundTrip(forward)
+	}
+	if c.prefix == nil {
+		resp, err := c.next.RoundTrip(forward)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		var response struct {
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, err
+		}
+		// Transmission sends the arguments first, the streaming decoder
+		// relies on it
+		c.prefix = append(append([]byte(`{"arguments":`), response.Arguments...), `,"result":"success","tag":`...)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(io.MultiReader(bytes.NewReader(c.prefix), strings.NewReader(strconv.FormatInt(request.Tag, 10)+"}"))),
+		Request:    req,
+	}, nil
+}
+
+// newBenchmarkClient connects to a server with benchmarkTorrents torrents,
+// whose torrent-get response is encoded before the benchmark starts.
+func newBenchmarkClient(b *testing.B) *transmission.Client {
+	server := transmissiontest.NewServer()
+	b.Cleanup(server.Close)
+	addTorrents(server, benchmarkTorrents)
+	canned := &cannedTransport{}
+	client, err := transmission.New(context.Background(), server.URL, transmission.WrapTransportOption(func(next http.RoundTripper) http.RoundTripper {
+		canned.next = next
+		return canned
+	}))
+	if err != nil {
+		b.Fatalf("New: %v", err)
+	}
+	if _, err := client.GetTorrents(context.Background()); err != nil {
+		b.Fatalf("GetTorrents: %v", err)
+	}
+	return client
+}
+
+// peakHeap runs fn and returns how far the heap grew above its size before,
+// sampled every 100µs. Unlike the allocations per op, it shows what has to
+// be held in memory at once.
+func peakHeap(fn func()) uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	base := stats.HeapAlloc
+	done := make(chan struct{})
+	peak := make(chan uint64)
+	go func() {
+		max := base
+		ticker := time.NewTicker(100 * time.Microsecond)
+		defer ticker.Stop()
+		for {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > max {
+				max = stats.HeapAlloc
+			}
+			select {
+			case <-done:
+				peak <- max
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	fn()
+	close(done)
+	return <-peak - base
+}
+
+func BenchmarkIterateTorrents(b *testing.B) {
+	client := newBenchmarkClient(b)
+	iterate := func() {
+		count := 0
+		err := client.IterateTorrents(context.Background(), func(transmission.Torrent) error {
+			count++
+			return nil
+		})
+		if err != nil || count != benchmarkTorrents {
+			b.Fatalf("got %d torrents, error %v", count, err)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		iterate()
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(peakHeap(iterate)), "peak-heap-B")
+}
+
+func BenchmarkGetTorrents(b *testing.B) {
+	client := newBenchmarkClient(b)
+	get := func() {
+		torrents, err := client.GetTorrents(context.Background())
+		if err != nil || len(torrents) != benchmarkTorrents {
+			b.Fatalf("got %d torrents, error %v", len(torrents), err)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		get()
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(peakHeap(get)), "peak-heap-B")
+}
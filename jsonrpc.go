@@ -0,0 +1,294 @@
+package transmission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+type Protocol int
+
+const (
+	// ProtocolAuto detects the protocol when the client connects.
+	ProtocolAuto Protocol = iota
+	// ProtocolLegacy is the original method/arguments/tag request format.
+	ProtocolLegacy
+	// ProtocolJSONRPC is the JSON-RPC 2.0 interface added in Transmission 4.1.
+	ProtocolJSONRPC
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolAuto:
+		return "auto"
+	case ProtocolLegacy:
+		return "legacy"
+	case ProtocolJSONRPC:
+		return "json-rpc"
+	}
+	return fmt.Sprintf("Protocol(%d)", int(p))
+}
+
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md
+type jsonRPCRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      int64       `json:"id"`
+}
+
+type jsonRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RPCError       `json:"error"`
+	ID      *int64          `json:"id"`
+}
+
+// RPCError is an error reported by the server. For the legacy protocol only
+// Message is set, holding the result string of the response.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	if e.Code == 0 && len(e.Data) == 0 {
+		return e.Message
+	}
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("rpc error %d: %s: %s", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// detectProtocol sends a JSON-RPC 2.0 session_get to the server. Daemons
+// older than 4.1 answer it in the legacy format, so the shape of the answer
+// tells which protocol the server speaks.
+func (t *Client) detectProtocol(ctx context.Context) (Protocol, error) {
+	body, err := json.Marshal(jsonRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "session_get",
+		Params: map[string][]string{
+			"fields": {"rpc_version"},
+		},
+		ID: t.nextTag(),
+	})
+	if err != nil {
+		return ProtocolAuto, fmt.Errorf("failed to encode request: %w", err)
+	}
+	resp, err := t.post(ctx, body)
+	if err != nil {
+		return ProtocolAuto, err
+	}
+	defer resp.Body.Close()
+	var response jsonRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return ProtocolAuto, err
+	}
+	if response.JSONRPC == "2.0" {
+		return ProtocolJSONRPC, nil
+	}
+	return ProtocolLegacy, nil
+}
+
+// callJSONRPC performs a legacy style call over JSON-RPC 2.0. Argument names
+// are converted to snake_case on the way out, and the result is converted back
+// and wrapped in a legacy response so callers can decode it unchanged.
+func (t *Client) callJSONRPC(ctx context.Context, requestMethod string, tag int64, requestArguments, response interface{}) error {
+	body, err := t.encodeRequest(requestMethod, tag, requestArguments)
+	if err != nil {
+		return err
+	}
+	resp, err := t.post(ctx, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var rpcResponse jsonRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResponse); err != nil {
+		return err
+	}
+	if rpcResponse.Error != nil {
+		return rpcResponse.Error
+	}
+	if err := checkTag(rpcResponse.ID, tag); err != nil {
+		return err
+	}
+	return decodeJSONRPCResult(rpcResponse.Result, response)
+}
+
+func decodeJSONRPCResult(result json.RawMessage, response interface{}) error {
+	var arguments interface{}
+	if len(result) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(result))
+		decoder.UseNumber()
+		if err := decoder.Decode(&arguments); err != nil {
+			return err
+		}
+	}
+	legacy := map[string]interface{}{
+		"result":    "success",
+		"arguments": arguments,
+	}
+	encoded, err := json.Marshal(legacyKeys(legacy, reflect.TypeOf(response)))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, response)
+}
+
+func toGeneric(v interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// snakeCase converts legacy camelCase and kebab-case names to snake_case.
+// Runs of capitals are treated as a single word, so "isUTP" becomes "is_utp".
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '-':
+			b.WriteByte('_')
+		case unicode.IsUpper(r):
+			if i > 0 && runes[i-1] != '-' && runes[i-1] != '_' &&
+				(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// snakeKeys rewrites the object keys of a decoded request to snake_case, along
+// with the field names listed in "fields" and the "recently-active" selector.
+func snakeKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if fields, ok := value.([]interface{}); ok && key == "fields" {
+				names := make([]interface{}, len(fields))
+				for i, field := range fields {
+					if name, ok := field.(string); ok {
+						names[i] = snakeCase(name)
+					} else {
+						names[i] = field
+					}
+				}
+				out[key] = names
+				continue
+			}
+			if selector, ok := value.(string); ok && key == "ids" {
+				out[key] = snakeCase(selector)
+				continue
+			}
+			out[snakeCase(key)] = snakeKeys(value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = snakeKeys(value)
+		}
+		return out
+	}
+	return v
+}
+
+// legacyKeys rewrites the snake_case object keys of a decoded response to the
+// names used by the json tags of t. Keys that don't belong to a known struct
+// field fall back to kebab-case, which is what session-get uses.
+func legacyKeys(v interface{}, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		var fields map[string]reflect.StructField
+		var elem reflect.Type
+		if t != nil {
+			switch t.Kind() {
+			case reflect.Struct:
+				fields = snakeFields(t)
+			case reflect.Map:
+				elem = t.Elem()
+			}
+		}
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if field, ok := fields[key]; ok {
+				out[jsonName(field)] = legacyKeys(value, field.Type)
+				continue
+			}
+			out[strings.Replace(key, "_", "-", -1)] = legacyKeys(value, elem)
+		}
+		return out
+	case []interface{}:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		if elem != nil && elem.Kind() == reflect.Struct {
+			if objects, ok := tableObjects(v); ok {
+				v = objects
+			}
+		}
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = legacyKeys(value, elem)
+		}
+		return out
+	}
+	return v
+}
+
+var snakeFieldCache sync.Map
+
+// snakeFields maps the snake_case form of every json field of t, including
+// the fields of embedded structs, to its struct field.
+func snakeFields(t reflect.Type) map[string]reflect.StructField {
+	if cached, ok := snakeFieldCache.Load(t); ok {
+		return cached.(map[string]reflect.StructField)
+	}
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for name, embedded := range snakeFields(f.Type) {
+				fields[name] = embedded
+			}
+			continue
+		}
+		name := jsonName(f)
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[snakeCase(name)] = f
+	}
+	snakeFieldCache.Store(t, fields)
+	return fields
+}
+
+func jsonName(f reflect.StructField) string {
+	return strings.Split(f.Tag.Get("json"), ",")[0]
+}
@@ -0,0 +1,242 @@
+package transmission_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"isUTP":              "is_utp",
+		"peer-limit":         "peer_limit",
+		"rpc-version":        "rpc_version",
+		"downloadDir":        "download_dir",
+		"torrent-get":        "torrent_get",
+		"hashString":         "hash_string",
+		"rateToPeer":         "rate_to_peer",
+		"files-wanted":       "files_wanted",
+		"seedRatioLimit":     "seed_ratio_limit",
+		"id":                 "id",
+		"recently-active":    "recently_active",
+		"percentDone":        "percent_done",
+		"peersGettingFromUs": "peers_getting_from_us",
+		"HTTPServer":         "http_server",
+		"ipv4Address":        "ipv4_address",
+		"already_snake":      "already_snake",
+	}
+	for name, want := range tests {
+		if got := transmission.SnakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSnakeKeys(t *testing.T) {
+	request := map[string]interface{}{
+		"ids":        "recently-active",
+		"fields":     []interface{}{"id", "isUTP", "downloadDir"},
+		"peer-limit": 5,
+		"trackerAdd": []interface{}{map[string]interface{}{"announceURL": "x"}},
+	}
+	want := map[string]interface{}{
+		"ids":         "recently_active",
+		"fields":      []interface{}{"id", "is_utp", "download_dir"},
+		"peer_limit":  5,
+		"tracker_add": []interface{}{map[string]interface{}{"announce_url": "x"}},
+	}
+	if got := transmission.SnakeKeys(request); !reflect.DeepEqual(got, want) {
+		t.Errorf("snakeKeys = %#v, want %#v", got, want)
+	}
+	// A list of IDs is left alone
+	ids := map[string]interface{}{"ids": []interface{}{1, "abc-def"}}
+	if got := transmission.SnakeKeys(ids); !reflect.DeepEqual(got, ids) {
+		t.Errorf("snakeKeys = %#v, want the IDs unchanged", got)
+	}
+}
+
+func TestLegacyKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		t     reflect.Type
+		want  interface{}
+	}{
+		{
+			name: "torrent fields",
+			value: map[string]interface{}{
+				"download_dir": "/d",
+				"peer_limit":   5,
+				"peers":        []interface{}{map[string]interface{}{"is_utp": true, "rate_to_peer": 1}},
+			},
+			t: reflect.TypeOf(transmission.Torrent{}),
+			want: map[string]interface{}{
+				"downloadDir": "/d",
+				"peer-limit":  5,
+				"peers":       []interface{}{map[string]interface{}{"isUTP": true, "rateToPeer": 1}},
+			},
+		},
+		{
+			name:  "session settings",
+			value: map[string]interface{}{"rpc_version": 18, "download_dir": "/d", "alt_speed_enabled": true},
+			t:     reflect.TypeOf(map[string]interface{}{}),
+			want:  map[string]interface{}{"rpc-version": 18, "download-dir": "/d", "alt-speed-enabled": true},
+		},
+		{
+			name:  "table format",
+			value: []interface{}{[]interface{}{"id", "is_stalled"}, []interface{}{1, true}},
+			t:     reflect.TypeOf([]transmission.Torrent{}),
+			want:  []interface{}{map[string]interface{}{"id": 1, "isStalled": true}},
+		},
+		{
+			name:  "unknown fields",
+			value: map[string]interface{}{"new_field": 1},
+			t:     reflect.TypeOf(transmission.Torrent{}),
+			want:  map[string]interface{}{"new-field": 1},
+		},
+	}
+	for _, tt := range tests {
+		if got := transmission.LegacyKeys(tt.value, tt.t); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: legacyKeys = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// jsonRPCServer answers session_get and torrent_get like Transmission 4.1,
+// and records the methods and params it was called with.
+type jsonRPCServer struct {
+	mu      sync.Mutex
+	methods []string
+	params  []map[string]interface{}
+}
+
+func (s *jsonRPCServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Transmission-Session-Id") == "" {
+		w.Header().Set("X-Transmission-Session-Id", "session")
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	var request struct {
+		JSONRPC string                 `json:"jsonrpc"`
+		Method  string                 `json:"method"`
+		Params  map[string]interface{} `json:"params"`
+		ID      int64                  `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.JSONRPC != "2.0" {
+		http.Error(w, "not a JSON-RPC request", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.methods = append(s.methods, request.Method)
+	s.params = append(s.params, request.Params)
+	s.mu.Unlock()
+	var result interface{}
+	switch request.Method {
+	case "session_get":
+		result = map[string]interface{}{
+			"rpc_version":       18,
+			"version":           "4.1.0",
+			"download_dir":      "/downloads",
+			"peer_limit_global": 200,
+			"alt_speed_enabled": true,
+		}
+	case "torrent_get":
+		result = map[string]interface{}{"torrents": []interface{}{map[string]interface{}{
+			"id":           1,
+			"hash_string":  "d55be2cd263efa84aeb9495333a4fabc428a4250",
+			"download_dir": "/downloads/tv",
+			"peer_limit":   50,
+			"is_stalled":   true,
+			"upload_ratio": 1.5,
+			"labels":       []string{"tv"},
+			"peers":        []interface{}{map[string]interface{}{"address": "10.0.0.1", "is_utp": true, "rate_to_peer": 1024}},
+			"file_stats":   []interface{}{map[string]interface{}{"bytes_completed": 5, "wanted": true, "priority": 1}},
+		}}}
+	default:
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"error":   map[string]interface{}{"code": -32601, "message": "Method not found"},
+			"id":      request.ID,
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "result": result, "id": request.ID})
+}
+
+func TestJSONRPCRoundTrip(t *testing.T) {
+	handler := &jsonRPCServer{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	ctx := context.Background()
+	client, err := transmission.New(ctx, server.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if client.Protocol() != transmission.ProtocolJSONRPC {
+		t.Errorf("detected protocol %v, want json-rpc", client.Protocol())
+	}
+	if client.DownloadDir != "/downloads" {
+		t.Errorf("DownloadDir = %q", client.DownloadDir)
+	}
+	session, err := client.GetSession(ctx)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	for key, want := range map[string]interface{}{"rpc-version": 18.0, "version": "4.1.0", "peer-limit-global": 200.0, "alt-speed-enabled": true} {
+		if session[key] != want {
+			t.Errorf("session %s = %v, want %v", key, session[key], want)
+		}
+	}
+
+	torrents, err := client.GetTorrents(ctx, transmission.ID(1))
+	if err != nil {
+		t.Fatalf("GetTorrents: %v", err)
+	}
+	if len(torrents) != 1 {
+		t.Fatalf("got %d torrents, want 1", len(torrents))
+	}
+	torrent := torrents[0]
+	if torrent.HashString != "d55be2cd263efa84aeb9495333a4fabc428a4250" || torrent.DownloadDir != "/downloads/tv" ||
+		torrent.PeerLimit != 50 || !torrent.IsStalled || torrent.UploadRatio != 1.5 || !reflect.DeepEqual(torrent.Labels, []string{"tv"}) {
+		t.Errorf("got torrent %+v", torrent)
+	}
+	if len(torrent.Peers) != 1 || !torrent.Peers[0].IsUTP || torrent.Peers[0].RateToPeer != 1024 {
+		t.Errorf("got peers %+v", torrent.Peers)
+	}
+	if len(torrent.FileStats) != 1 || torrent.FileStats[0].BytesCompleted != 5 || !torrent.FileStats[0].Wanted {
+		t.Errorf("got file stats %+v", torrent.FileStats)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	last := len(handler.methods) - 1
+	if handler.methods[last] != "torrent_get" {
+		t.Fatalf("last method %q, want torrent_get", handler.methods[last])
+	}
+	fields, _ := handler.params[last]["fields"].([]interface{})
+	have := make(map[interface{}]bool)
+	for _, field := range fields {
+		have[field] = true
+	}
+	for _, field := range []string{"hash_string", "download_dir", "peer_limit", "is_stalled", "file_stats"} {
+		if !have[field] {
+			t.Errorf("torrent_get didn't request %s in %v", field, fields)
+		}
+	}
+	if have["hashString"] || have["peer-limit"] {
+		t.Errorf("torrent_get requested legacy field names: %v", fields)
+	}
+}
+
+func TestDetectLegacyProtocol(t *testing.T) {
+	client, _ := newTestClient(t)
+	if client.Protocol() != transmission.ProtocolLegacy {
+		t.Errorf("detected protocol %v against a legacy daemon, want legacy", client.Protocol())
+	}
+}
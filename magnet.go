@@ -0,0 +1,217 @@
+package transmission
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// btmhPrefix is the multihash prefix of a SHA-256 digest, which v2 info
+// hashes are.
+const btmhPrefix = "1220"
+
+// Magnet is a parsed magnet link.
+type Magnet struct {
+	// InfoHash is the v1 info hash (xt=urn:btih) in lower case hex
+	InfoHash string
+	// InfoHashV2 is the v2 info hash (xt=urn:btmh) in lower case hex, without
+	// the multihash prefix
+	InfoHashV2  string
+	DisplayName string
+	Trackers    []string
+	WebSeeds    []string
+	// Length is the exact length of the content (xl), 0 if unknown
+	Length int64
+	// SelectOnly lists the file indices and ranges to download (so), like
+	// "0,2,4-6"
+	SelectOnly string
+	// Params holds any other parameters, which String keeps
+	Params url.Values
+}
+
+// ParseMagnet parses a magnet link. It needs a v1 or v2 info hash, and
+// accepts v1 hashes in hex or base32. Indexed parameters like tr.1 are read
+// like tr.
+func ParseMagnet(link string) (*Magnet, error) {
+	query := strings.TrimPrefix(link, "magnet:?")
+	if query == link {
+		return nil, fmt.Errorf("invalid magnet link %q: must start with magnet:?", link)
+	}
+	m := &Magnet{}
+	for _, param := range strings.Split(query, "&") {
+		if param == "" {
+			continue
+		}
+		parts := strings.SplitN(param, "=", 2)
+		key, err := url.QueryUnescape(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid magnet link parameter %q: %w", parts[0], err)
+		}
+		var value string
+		if len(parts) == 2 {
+			if value, err = url.QueryUnescape(parts[1]); err != nil {
+				return nil, fmt.Errorf("invalid magnet link %s: %w", key, err)
+			}
+		}
+		if err := m.set(key, value); err != nil {
+			return nil, fmt.Errorf("invalid magnet link %s: %w", key, err)
+		}
+	}
+	if m.InfoHash == "" && m.InfoHashV2 == "" {
+		return nil, fmt.Errorf("invalid magnet link %q: no btih or btmh info hash", link)
+	}
+	return m, nil
+}
+
+func (m *Magnet) set(key, value string) error {
+	name := key
+	if i := strings.IndexByte(key, '.'); i > 0 {
+		if _, err := strconv.Atoi(key[i+1:]); err == nil {
+			name = key[:i]
+		}
+	}
+	switch name {
+	case "xt":
+		switch {
+		case strings.HasPrefix(value, "urn:btih:"):
+			hash, err := parseBTIH(strings.TrimPrefix(value, "urn:btih:"))
+			if err != nil {
+				return err
+			}
+			m.InfoHash = hash
+		case strings.HasPrefix(value, "urn:btmh:"):
+			hash := strings.ToLower(strings.TrimPrefix(value, "urn:btmh:"))
+			if !strings.HasPrefix(hash, btmhPrefix) || len(hash) != len(btmhPrefix)+64 || !isHex(hash) {
+				return fmt.Errorf("%q is not a SHA-256 multihash", hash)
+			}
+			m.InfoHashV2 = strings.TrimPrefix(hash, btmhPrefix)
+		default:
+			m.addParam(key, value)
+		}
+	case "dn":
+		m.DisplayName = value
+	case "tr":
+		m.Trackers = append(m.Trackers, value)
+	case "ws":
+		m.WebSeeds = append(m.WebSeeds, value)
+	case "xl":
+		length, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || length < 0 {
+			return fmt.Errorf("%q is not a length", value)
+		}
+		m.Length = length
+	case "so":
+		for _, selection := range strings.Split(value, ",") {
+			for _, index := range strings.SplitN(selection, "-", 2) {
+				if _, err := strconv.ParseUint(index, 10, 32); err != nil {
+					return fmt.Errorf("%q is not a list of file indices", value)
+				}
+			}
+		}
+		m.SelectOnly = value
+	default:
+		m.addParam(key, value)
+	}
+	return nil
+}
+
+func (m *Magnet) addParam(key, value string) {
+	if m.Params == nil {
+		m.Params = make(url.Values)
+	}
+	m.Params.Add(key, value)
+}
+
+// parseBTIH returns a hex or base32 encoded v1 info hash in lower case hex.
+func parseBTIH(hash string) (string, error) {
+	switch len(hash) {
+	case 40:
+		if isHex(hash) {
+			return strings.ToLower(hash), nil
+		}
+	case 32:
+		if decoded, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash)); err == nil {
+			return hex.EncodeToString(decoded), nil
+		}
+	}
+	return "", fmt.Errorf("%q is not a hex or base32 SHA1 info hash", hash)
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// HashString returns the info hash in the form of Torrent.HashString: the v1
+// hash, or for v2 only links the v2 hash truncated to 20 bytes.
+func (m *Magnet) HashString() string {
+	if m.InfoHash != "" {
+		return m.InfoHash
+	}
+	return m.InfoHashV2[:40]
+}
+
+// AddTrackers appends the trackers the link doesn't already have.
+func (m *Magnet) AddTrackers(trackers ...string) {
+	have := make(map[string]bool, len(m.Trackers))
+	for _, tracker := range m.Trackers {
+		have[tracker] = true
+	}
+	for _, tracker := range trackers {
+		if !have[tracker] {
+			have[tracker] = true
+			m.Trackers = append(m.Trackers, tracker)
+		}
+	}
+}
+
+// String formats the magnet link, with the info hashes first and v1 hashes in
+// hex.
+func (m *Magnet) String() string {
+	var params []string
+	add := func(key, value string) {
+		params = append(params, key+"="+url.QueryEscape(value))
+	}
+	// The hashes only hold hex digits, so the URNs are left unescaped
+	if m.InfoHash != "" {
+		params = append(params, "xt=urn:btih:"+m.InfoHash)
+	}
+	if m.InfoHashV2 != "" {
+		params = append(params, "xt=urn:btmh:"+btmhPrefix+m.InfoHashV2)
+	}
+	if m.DisplayName != "" {
+		add("dn", m.DisplayName)
+	}
+	if m.Length > 0 {
+		add("xl", strconv.FormatInt(m.Length, 10))
+	}
+	for _, tracker := range m.Trackers {
+		add("tr", tracker)
+	}
+	for _, webSeed := range m.WebSeeds {
+		add("ws", webSeed)
+	}
+	if m.SelectOnly != "" {
+		add("so", m.SelectOnly)
+	}
+	keys := make([]string, 0, len(m.Params))
+	for key := range m.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range m.Params[key] {
+			add(url.QueryEscape(key), value)
+		}
+	}
+	return "magnet:?" + strings.Join(params, "&")
+}
+
+// Magnet parses the torrent's MagnetLink.
+func (t *Torrent) Magnet() (*Magnet, error) {
+	return ParseMagnet(t.MagnetLink)
+}
@@ -0,0 +1,139 @@
+package transmission_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+const (
+	testHash   = "d55be2cd263efa84aeb9495333a4fabc428a4250"
+	testHashV2 = "0b8fc5436344a1f95980e56af8c7176cc66be4478dc40a4ae9e306119f733220"
+)
+
+func TestParseMagnetInfoHashes(t *testing.T) {
+	tests := []struct {
+		link       string
+		hash, v2   string
+		hashString string
+	}{
+		{"magnet:?xt=urn:btih:" + testHash, testHash, "", testHash},
+		{"magnet:?xt=urn:btih:D55BE2CD263EFA84AEB9495333A4FABC428A4250", testHash, "", testHash},
+		// The base32 encoding of the same hash, in both cases
+		{"magnet:?xt=urn:btih:2VN6FTJGH35IJLVZJFJTHJH2XRBIUQSQ", testHash, "", testHash},
+		{"magnet:?xt=urn:btih:2vn6ftjgh35ijlvzjfjthjh2xrbiuqsq", testHash, "", testHash},
+		{"magnet:?xt=urn:btmh:1220" + testHashV2, "", testHashV2, testHashV2[:40]},
+		{"magnet:?xt=urn:btmh:1220" + "0B8FC5436344A1F95980E56AF8C7176CC66BE4478DC40A4AE9E306119F733220", "", testHashV2, testHashV2[:40]},
+		{"magnet:?xt=urn:btih:" + testHash + "&xt=urn:btmh:1220" + testHashV2, testHash, testHashV2, testHash},
+		{"magnet:?xt.1=urn:btih:" + testHash + "&xt.2=urn:btmh:1220" + testHashV2, testHash, testHashV2, testHash},
+	}
+	for _, tt := range tests {
+		m, err := transmission.ParseMagnet(tt.link)
+		if err != nil {
+			t.Errorf("ParseMagnet(%q): %v", tt.link, err)
+			continue
+		}
+		if m.InfoHash != tt.hash || m.InfoHashV2 != tt.v2 || m.HashString() != tt.hashString {
+			t.Errorf("ParseMagnet(%q) gave hashes %q, %q and hash string %q", tt.link, m.InfoHash, m.InfoHashV2, m.HashString())
+		}
+	}
+}
+
+func TestParseMagnetErrors(t *testing.T) {
+	for _, link := range []string{
+		"http://example.com/?xt=urn:btih:" + testHash,
+		"magnet:?dn=no+hash",
+		"magnet:?xt=urn:sha1:" + testHash,
+		"magnet:?xt=urn:btih:" + testHash[:39],
+		"magnet:?xt=urn:btih:" + testHash[:39] + "g",
+		"magnet:?xt=urn:btih:2VN6FTJGH35IJLVZJFJTHJH2XRBIUQS1",
+		// The multihash prefix is required, and must be SHA-256 of 32 bytes
+		"magnet:?xt=urn:btmh:" + testHashV2,
+		"magnet:?xt=urn:btmh:1114" + testHashV2[:40],
+		"magnet:?xt=urn:btmh:1220" + testHashV2[:62],
+		"magnet:?xt=urn:btih:" + testHash + "&xl=-1",
+		"magnet:?xt=urn:btih:" + testHash + "&xl=big",
+		"magnet:?xt=urn:btih:" + testHash + "&so=",
+		"magnet:?xt=urn:btih:" + testHash + "&so=1,,2",
+		"magnet:?xt=urn:btih:" + testHash + "&so=a",
+		"magnet:?xt=urn:btih:" + testHash + "&so=-1",
+		"magnet:?xt=urn:btih:" + testHash + "&so=1-",
+		"magnet:?xt=urn:btih:" + testHash + "&so=1-2-3",
+		"magnet:?xt=urn:btih:" + testHash + "&dn=%zz",
+	} {
+		if m, err := transmission.ParseMagnet(link); err == nil {
+			t.Errorf("ParseMagnet(%q) = %+v, want an error", link, m)
+		}
+	}
+}
+
+func TestParseMagnetParams(t *testing.T) {
+	link := "magnet:?xt=urn:btih:" + testHash +
+		"&dn=Debian+11%2B&xl=400556032" +
+		"&tr=http%3A%2F%2Fa%2Fannounce&tr.1=udp%3A%2F%2Fb%3A80&tr.2=http%3A%2F%2Fc%2Fannounce" +
+		"&ws=http%3A%2F%2Fseed%2F&so=0,2,4-6&x.pe=1.2.3.4%3A5&kt=linux+iso"
+	m, err := transmission.ParseMagnet(link)
+	if err != nil {
+		t.Fatalf("ParseMagnet: %v", err)
+	}
+	if m.DisplayName != "Debian 11+" || m.Length != 400556032 || m.SelectOnly != "0,2,4-6" {
+		t.Errorf("got name %q, length %d, select only %q", m.DisplayName, m.Length, m.SelectOnly)
+	}
+	wantTrackers := []string{"http://a/announce", "udp://b:80", "http://c/announce"}
+	if !reflect.DeepEqual(m.Trackers, wantTrackers) {
+		t.Errorf("Trackers = %q, want %q", m.Trackers, wantTrackers)
+	}
+	if !reflect.DeepEqual(m.WebSeeds, []string{"http://seed/"}) {
+		t.Errorf("WebSeeds = %q", m.WebSeeds)
+	}
+	// x.pe isn't indexed, its suffix isn't a number
+	if m.Params.Get("x.pe") != "1.2.3.4:5" || m.Params.Get("kt") != "linux iso" {
+		t.Errorf("Params = %v", m.Params)
+	}
+}
+
+func TestMagnetStringRoundTrip(t *testing.T) {
+	links := []string{
+		"magnet:?xt=urn:btih:" + testHash,
+		"magnet:?xt=urn:btmh:1220" + testHashV2,
+		"magnet:?xt=urn:btih:" + testHash + "&xt=urn:btmh:1220" + testHashV2 +
+			"&dn=Debian+11%2B&xl=400556032&tr=http%3A%2F%2Fa%2Fannounce&tr=udp%3A%2F%2Fb%3A80" +
+			"&ws=http%3A%2F%2Fseed%2F&so=0%2C2%2C4-6&kt=linux+iso&x.pe=1.2.3.4%3A5",
+	}
+	for _, link := range links {
+		m, err := transmission.ParseMagnet(link)
+		if err != nil {
+			t.Fatalf("ParseMagnet(%q): %v", link, err)
+		}
+		if got := m.String(); got != link {
+			t.Errorf("String() = %q, want %q", got, link)
+		}
+	}
+	// Base32 hashes and indexed parameters are written back in hex and
+	// without index
+	m, err := transmission.ParseMagnet("magnet:?tr.1=http%3A%2F%2Fa%2Fannounce&xt=urn:btih:2VN6FTJGH35IJLVZJFJTHJH2XRBIUQSQ")
+	if err != nil {
+		t.Fatalf("ParseMagnet: %v", err)
+	}
+	want := "magnet:?xt=urn:btih:" + testHash + "&tr=http%3A%2F%2Fa%2Fannounce"
+	if got := m.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	again, err := transmission.ParseMagnet(m.String())
+	if err != nil || !reflect.DeepEqual(again, m) {
+		t.Errorf("parsing String() gave %+v, %v, want %+v", again, err, m)
+	}
+}
+
+func TestMagnetAddTrackers(t *testing.T) {
+	m, err := transmission.ParseMagnet("magnet:?xt=urn:btih:" + testHash + "&tr=http%3A%2F%2Fa%2Fannounce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.AddTrackers("http://b/announce", "http://a/announce", "http://b/announce")
+	want := []string{"http://a/announce", "http://b/announce"}
+	if !reflect.DeepEqual(m.Trackers, want) {
+		t.Errorf("Trackers = %q, want %q", m.Trackers, want)
+	}
+}
@@ -0,0 +1,368 @@
+package metainfo
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc/bencode"
+)
+
+// Piece length bounds for PieceLengthOption and the automatic choice.
+const (
+	MinPieceLength = 16 << 10
+	MaxPieceLength = 16 << 20
+)
+
+// targetPieces is the number of pieces the automatic piece length aims for.
+const targetPieces = 1500
+
+type creator struct {
+	pieceLength int64
+	trackers    []string
+	webSeeds    []string
+	private     bool
+	comment     string
+	createdBy   string
+	source      string
+	workers     int
+	onProgress  func(done, total int)
+}
+
+type CreateOption func(*creator)
+
+// PieceLengthOption sets the piece length, a power of two between
+// MinPieceLength and MaxPieceLength. By default it is picked for the content
+// to have about 1500 pieces.
+func PieceLengthOption(pieceLength int64) CreateOption {
+	return func(c *creator) {
+		c.pieceLength = pieceLength
+	}
+}
+
+// TrackersOption adds announce URLs, each as a tier of its own.
+func TrackersOption(trackers ...string) CreateOption {
+	return func(c *creator) {
+		c.trackers = append(c.trackers, trackers...)
+	}
+}
+
+// WebSeedsOption adds web seed URLs, as in BEP 19.
+func WebSeedsOption(urls ...string) CreateOption {
+	return func(c *creator) {
+		c.webSeeds = append(c.webSeeds, urls...)
+	}
+}
+
+// PrivateOption marks the torrent private, so clients only get peers from its
+// trackers.
+func PrivateOption() CreateOption {
+	return func(c *creator) {
+		c.private = true
+	}
+}
+
+func CommentOption(comment string) CreateOption {
+	return func(c *creator) {
+		c.comment = comment
+	}
+}
+
+func CreatedByOption(createdBy string) CreateOption {
+	return func(c *creator) {
+		c.createdBy = createdBy
+	}
+}
+
+// SourceOption sets the source field of the info dictionary, which private
+// trackers use to give cross-seeded torrents different info hashes.
+func SourceOption(source string) CreateOption {
+	return func(c *creator) {
+		c.source = source
+	}
+}
+
+// WorkersOption sets how many pieces are hashed at once, the number of CPUs
+// by default.
+func WorkersOption(workers int) CreateOption {
+	return func(c *creator) {
+		c.workers = workers
+	}
+}
+
+// CreateProgressOption is called as pieces are hashed, with the number hashed
+// so far.
+func CreateProgressOption(onProgress func(done, total int)) CreateOption {
+	return func(c *creator) {
+		c.onProgress = onProgress
+	}
+}
+
+type sourceFile struct {
+	path string
+	File
+}
+
+// Create makes a v1 torrent of a file or directory. Files of a directory are
+// added in path order, and hidden files and anything that isn't a regular
+// file are left out. Pieces are read sequentially and hashed in parallel.
+func Create(ctx context.Context, root string, opts ...CreateOption) (*MetaInfo, error) {
+	c := &creator{workers: runtime.NumCPU()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.workers < 1 {
+		c.workers = 1
+	}
+	// The torrent is named after the last element of the absolute path, as
+	// relative paths like . don't have a usable one
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(root)
+	if err := validPathElement(name); err != nil {
+		return nil, fmt.Errorf("can't name a torrent after %s: %w", root, err)
+	}
+	files, err := listFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, f := range files {
+		total += f.Length
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%s has no content", root)
+	}
+	if c.pieceLength == 0 {
+		c.pieceLength = choosePieceLength(total)
+	}
+	if c.pieceLength < MinPieceLength || c.pieceLength > MaxPieceLength || c.pieceLength&(c.pieceLength-1) != 0 {
+		return nil, fmt.Errorf("piece length %d is not a power of two between %d and %d", c.pieceLength, MinPieceLength, MaxPieceLength)
+	}
+	pieces, err := c.hashPieces(ctx, files, total)
+	if err != nil {
+		return nil, err
+	}
+	info := Info{
+		Name:        name,
+		PieceLength: c.pieceLength,
+		Pieces:      pieces,
+		Private:     c.private,
+		Source:      c.source,
+	}
+	if len(files) == 1 && files[0].path == root {
+		info.Length = files[0].Length
+	} else {
+		for _, f := range files {
+			info.Files = append(info.Files, f.File)
+		}
+	}
+	infoBytes, err := bencode.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	m := &MetaInfo{
+		Info:         info,
+		InfoBytes:    infoBytes,
+		WebSeeds:     c.webSeeds,
+		Comment:      c.comment,
+		CreatedBy:    c.createdBy,
+		CreationDate: time.Unix(time.Now().Unix(), 0),
+	}
+	for _, tracker := range c.trackers {
+		m.AnnounceList = append(m.AnnounceList, []string{tracker})
+	}
+	if len(c.trackers) > 0 {
+		m.Announce = c.trackers[0]
+	}
+	return m, nil
+}
+
+// listFiles returns the file, or the files of the directory with their paths
+// relative to it.
+func listFiles(root string) ([]sourceFile, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []sourceFile{{path: root, File: File{Length: info.Size(), Path: []string{info.Name()}}}}, nil
+	}
+	var files []sourceFile
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") && path != root {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, sourceFile{
+			path: path,
+			File: File{Length: info.Size(), Path: strings.Split(filepath.ToSlash(rel), "/")},
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// choosePieceLength returns the smallest power of two piece length giving at
+// most targetPieces pieces, within the bounds.
+func choosePieceLength(total int64) int64 {
+	pieceLength := int64(MinPieceLength)
+	for pieceLength < MaxPieceLength && (total+pieceLength-1)/pieceLength > targetPieces {
+		pieceLength *= 2
+	}
+	return pieceLength
+}
+
+type piece struct {
+	index int
+	data  []byte
+}
+
+// hashPieces reads the files as one stream cut into pieces, and hashes the
+// pieces on the workers.
+func (c *creator) hashPieces(ctx context.Context, files []sourceFile, total int64) ([]byte, error) {
+	count := int((total + c.pieceLength - 1) / c.pieceLength)
+	hashes := make([]byte, count*sha1.Size)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	buffers := sync.Pool{New: func() interface{} {
+		return make([]byte, c.pieceLength)
+	}}
+	work := make(chan piece, c.workers)
+	var readErr error
+	go func() {
+		defer close(work)
+		readErr = readPieces(ctx, files, c.pieceLength, &buffers, work)
+	}()
+
+	var mu sync.Mutex
+	done := 0
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range work {
+				sum := sha1.Sum(p.data)
+				copy(hashes[p.index*sha1.Size:], sum[:])
+				buffers.Put(p.data[:cap(p.data)])
+				if c.onProgress != nil {
+					mu.Lock()
+					done++
+					c.onProgress(done, count)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if readErr != nil {
+		return nil, readErr
+	}
+	return hashes, nil
+}
+
+// readPieces sends the content of the files in pieces, until it is all read
+// or ctx is done.
+func readPieces(ctx context.Context, files []sourceFile, pieceLength int64, buffers *sync.Pool, work chan<- piece) error {
+	current := piece{data: buffers.Get().([]byte)[:0]}
+	send := func() error {
+		select {
+		case work <- current:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		current = piece{index: current.index + 1, data: buffers.Get().([]byte)[:0]}
+		return nil
+	}
+	for _, f := range files {
+		file, err := os.Open(f.path)
+		if err != nil {
+			return err
+		}
+		remaining := f.Length
+		for remaining > 0 {
+			n := pieceLength - int64(len(current.data))
+			if n > remaining {
+				n = remaining
+			}
+			start := len(current.data)
+			current.data = current.data[:start+int(n)]
+			if _, err := io.ReadFull(file, current.data[start:]); err != nil {
+				file.Close()
+				if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+					return fmt.Errorf("%s changed while it was read", f.path)
+				}
+				return err
+			}
+			remaining -= n
+			if int64(len(current.data)) == pieceLength {
+				if err := send(); err != nil {
+					file.Close()
+					return err
+				}
+			}
+		}
+		file.Close()
+	}
+	if len(current.data) > 0 {
+		return send()
+	}
+	return nil
+}
+
+// Bytes encodes the torrent file.
+func (m *MetaInfo) Bytes() ([]byte, error) {
+	infoBytes := m.InfoBytes
+	if len(infoBytes) == 0 {
+		var err error
+		if infoBytes, err = bencode.Marshal(m.Info); err != nil {
+			return nil, err
+		}
+	}
+	f := file{
+		Announce:     m.Announce,
+		AnnounceList: m.AnnounceList,
+		Comment:      m.Comment,
+		CreatedBy:    m.CreatedBy,
+		Info:         infoBytes,
+		PieceLayers:  m.PieceLayers,
+	}
+	if !m.CreationDate.IsZero() {
+		f.CreationDate = m.CreationDate.Unix()
+	}
+	if len(m.WebSeeds) > 0 {
+		urlList, err := bencode.Marshal(m.WebSeeds)
+		if err != nil {
+			return nil, err
+		}
+		f.URLList = urlList
+	}
+	return bencode.Marshal(f)
+}
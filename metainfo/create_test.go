@@ -0,0 +1,201 @@
+package metainfo_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc/metainfo"
+)
+
+// writeContent writes a directory of three files adding up to 40100 bytes,
+// three pieces of 16 KiB: the second spans all three files and the last is
+// short. A hidden file is left out of torrents.
+func writeContent(t *testing.T) (dir string, content []byte) {
+	t.Helper()
+	parent, err := ioutil.TempDir("", "metainfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(parent) })
+	dir = filepath.Join(parent, "content")
+	files := []struct {
+		path string
+		data []byte
+	}{
+		{"a.bin", pattern(10000, 7, 0)},
+		{"c.txt", bytes.Repeat([]byte("x"), 100)},
+		{"sub/b.bin", pattern(30000, 13, 5)},
+		{".hidden", []byte("left out")},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, f.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(f.path, ".") {
+			content = append(content, f.data...)
+		}
+	}
+	return dir, content
+}
+
+func pattern(n, multiplier, offset int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i*multiplier + offset)
+	}
+	return data
+}
+
+// The info hashes were computed with Python's hashlib over the same content.
+func TestCreate(t *testing.T) {
+	dir, content := writeContent(t)
+	tests := []struct {
+		name  string
+		path  string
+		data  []byte
+		files []string
+		hash  string
+	}{
+		{"directory", dir, content, []string{"a.bin", "c.txt", "sub/b.bin"}, "9f070eb93e605f21123f78b084df9e8638441a50"},
+		{"single file", filepath.Join(dir, "sub", "b.bin"), content[10100:], []string{"b.bin"}, "a0d6a8259c5e47ff99f37d7ffec2b7194ecea65f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, workers := range []int{1, 4} {
+				m, err := metainfo.Create(context.Background(), tt.path,
+					metainfo.PieceLengthOption(metainfo.MinPieceLength), metainfo.WorkersOption(workers))
+				if err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				if err := m.Validate(); err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				if got := m.HashString(); got != tt.hash {
+					t.Errorf("%d workers: HashString() = %s, want %s", workers, got, tt.hash)
+				}
+				var want []byte
+				for i := 0; i < len(tt.data); i += metainfo.MinPieceLength {
+					end := i + metainfo.MinPieceLength
+					if end > len(tt.data) {
+						end = len(tt.data)
+					}
+					sum := sha1.Sum(tt.data[i:end])
+					want = append(want, sum[:]...)
+				}
+				if !bytes.Equal(m.Info.Pieces, want) {
+					t.Errorf("%d workers: got %d piece hashes, want the %d of the content", workers, len(m.Info.Pieces)/sha1.Size, len(want)/sha1.Size)
+				}
+				var paths []string
+				for _, f := range m.Files() {
+					paths = append(paths, strings.Join(f.Path, "/"))
+				}
+				if !reflect.DeepEqual(paths, tt.files) {
+					t.Errorf("got files %q, want %q", paths, tt.files)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateNamesRelativePaths(t *testing.T) {
+	dir, _ := writeContent(t)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{".", "./", "sub/..", "../content"} {
+		m, err := metainfo.Create(context.Background(), path)
+		if err != nil {
+			t.Fatalf("Create(%q): %v", path, err)
+		}
+		if m.Info.Name != "content" {
+			t.Errorf("Create(%q) named the torrent %q, want content", path, m.Info.Name)
+		}
+	}
+	if _, err := metainfo.Create(context.Background(), string(filepath.Separator)); err == nil {
+		t.Error("Create named a torrent after the root directory")
+	}
+}
+
+func TestCreatePieceLength(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metainfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "content.bin")
+	tests := []struct {
+		size int64
+		want int64
+	}{
+		{1, 16 << 10},
+		{1500 * 16 << 10, 16 << 10},
+		// One byte more would make 1501 pieces
+		{1500*16<<10 + 1, 32 << 10},
+		{3000 * 32 << 10, 64 << 10},
+	}
+	for _, tt := range tests {
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Truncate(path, tt.size); err != nil {
+			t.Fatal(err)
+		}
+		m, err := metainfo.Create(context.Background(), path)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if m.Info.PieceLength != tt.want || m.PieceCount() > 1500 {
+			t.Errorf("%d bytes got %d pieces of %d, want pieces of %d", tt.size, m.PieceCount(), m.Info.PieceLength, tt.want)
+		}
+	}
+	for _, pieceLength := range []int64{metainfo.MinPieceLength / 2, 3 << 14, metainfo.MaxPieceLength * 2} {
+		if _, err := metainfo.Create(context.Background(), path, metainfo.PieceLengthOption(pieceLength)); err == nil {
+			t.Errorf("Create accepted a piece length of %d", pieceLength)
+		}
+	}
+}
+
+func TestCreateBytesRoundTrip(t *testing.T) {
+	dir, _ := writeContent(t)
+	m, err := metainfo.Create(context.Background(), dir,
+		metainfo.TrackersOption("http://a/announce", "http://b/announce"),
+		metainfo.WebSeedsOption("http://seed/"),
+		metainfo.PrivateOption(),
+		metainfo.CommentOption("comment"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	data, err := m.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	parsed, err := metainfo.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if parsed.HashString() != m.HashString() || !parsed.Info.Private || parsed.Comment != "comment" {
+		t.Errorf("parsed %+v, want the created torrent", parsed)
+	}
+	if got := parsed.Trackers(); !reflect.DeepEqual(got, [][]string{{"http://a/announce"}, {"http://b/announce"}}) {
+		t.Errorf("Trackers() = %q", got)
+	}
+	if !reflect.DeepEqual(parsed.WebSeeds, []string{"http://seed/"}) {
+		t.Errorf("WebSeeds = %q", parsed.WebSeeds)
+	}
+}
@@ -0,0 +1,316 @@
+// Package metainfo parses and validates .torrent files, and computes their
+// info hashes.
+package metainfo
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc/bencode"
+)
+
+// MetaInfo is the content of a .torrent file.
+type MetaInfo struct {
+	Info Info
+	// InfoBytes is the encoded info dictionary the info hashes are computed
+	// over, exactly as it appears in the file.
+	InfoBytes    []byte
+	Announce     string
+	AnnounceList [][]string
+	WebSeeds     []string
+	Comment      string
+	CreatedBy    string
+	// CreationDate is zero if the file doesn't have one
+	CreationDate time.Time
+	// PieceLayers holds the v2 piece hashes of each file, by pieces root
+	PieceLayers map[string][]byte
+}
+
+// Info is the info dictionary of a .torrent file, which identifies the
+// torrent's content.
+type Info struct {
+	Name        string `bencode:"name"`
+	PieceLength int64  `bencode:"piece length"`
+	// Pieces are the concatenated SHA-1 hashes of the v1 pieces
+	Pieces  []byte `bencode:"pieces,omitempty"`
+	Private bool   `bencode:"private,omitempty"`
+	Source  string `bencode:"source,omitempty"`
+	// Length is set for single file v1 torrents
+	Length int64 `bencode:"length,omitempty"`
+	// Files is set for multiple file v1 torrents
+	Files []File `bencode:"files,omitempty"`
+	// MetaVersion is 2 for v2 and hybrid torrents
+	MetaVersion int `bencode:"meta version,omitempty"`
+	// FileTree is the v2 file tree, see Files
+	FileTree map[string]interface{} `bencode:"file tree,omitempty"`
+}
+
+// File is a file of a torrent.
+type File struct {
+	Length int64    `bencode:"length"`
+	Path   []string `bencode:"path"`
+	// Attr holds p for the padding files of hybrid torrents
+	Attr string `bencode:"attr,omitempty"`
+	// PiecesRoot is the root hash of the file's v2 piece tree
+	PiecesRoot []byte `bencode:"-"`
+}
+
+// IsPadding is true for padding files, which align files to pieces and
+// aren't part of the content.
+func (f File) IsPadding() bool {
+	return strings.Contains(f.Attr, "p")
+}
+
+type file struct {
+	Announce     string             `bencode:"announce,omitempty"`
+	AnnounceList [][]string         `bencode:"announce-list,omitempty"`
+	Comment      string             `bencode:"comment,omitempty"`
+	CreatedBy    string             `bencode:"created by,omitempty"`
+	CreationDate int64              `bencode:"creation date,omitempty"`
+	Info         bencode.RawMessage `bencode:"info"`
+	PieceLayers  map[string][]byte  `bencode:"piece layers,omitempty"`
+	URLList      bencode.RawMessage `bencode:"url-list,omitempty"`
+}
+
+// Parse decodes a .torrent file. It doesn't validate the content, see
+// Validate.
+func Parse(data []byte) (*MetaInfo, error) {
+	var f file
+	if err := bencode.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid torrent file: %w", err)
+	}
+	if len(f.Info) == 0 {
+		return nil, errors.New("invalid torrent file: no info dictionary")
+	}
+	m := &MetaInfo{
+		InfoBytes:    f.Info,
+		Announce:     f.Announce,
+		AnnounceList: f.AnnounceList,
+		Comment:      f.Comment,
+		CreatedBy:    f.CreatedBy,
+		PieceLayers:  f.PieceLayers,
+	}
+	if err := bencode.Unmarshal(f.Info, &m.Info); err != nil {
+		return nil, fmt.Errorf("invalid info dictionary: %w", err)
+	}
+	if f.CreationDate > 0 {
+		m.CreationDate = time.Unix(f.CreationDate, 0)
+	}
+	// url-list is a single URL or a list of them
+	if len(f.URLList) > 0 {
+		var single string
+		if err := bencode.Unmarshal(f.URLList, &single); err == nil {
+			if single != "" {
+				m.WebSeeds = []string{single}
+			}
+		} else if err := bencode.Unmarshal(f.URLList, &m.WebSeeds); err != nil {
+			return nil, fmt.Errorf("invalid url-list: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// Load reads and decodes a .torrent file.
+func Load(path string) (*MetaInfo, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// IsV1 is true if the torrent has v1 piece hashes.
+func (m *MetaInfo) IsV1() bool {
+	return len(m.Info.Pieces) > 0
+}
+
+// IsV2 is true for v2 and hybrid torrents.
+func (m *MetaInfo) IsV2() bool {
+	return m.Info.MetaVersion == 2 && len(m.Info.FileTree) > 0
+}
+
+// InfoHashV1 returns the SHA-1 info hash, if the torrent is v1 or hybrid.
+func (m *MetaInfo) InfoHashV1() ([20]byte, bool) {
+	if !m.IsV1() {
+		return [20]byte{}, false
+	}
+	return sha1.Sum(m.InfoBytes), true
+}
+
+// InfoHashV2 returns the SHA-256 info hash, if the torrent is v2 or hybrid.
+func (m *MetaInfo) InfoHashV2() ([32]byte, bool) {
+	if !m.IsV2() {
+		return [32]byte{}, false
+	}
+	return sha256.Sum256(m.InfoBytes), true
+}
+
+// HashString returns the info hash in the form of Torrent.HashString: the v1
+// hash in hex, or for v2 only torrents the v2 hash truncated to 20 bytes.
+func (m *MetaInfo) HashString() string {
+	if hash, ok := m.InfoHashV1(); ok {
+		return hex.EncodeToString(hash[:])
+	}
+	hash, _ := m.InfoHashV2()
+	return hex.EncodeToString(hash[:20])
+}
+
+// Trackers returns the announce URLs by tier. The announce-list takes
+// precedence over the single announce URL, as in BEP 12.
+func (m *MetaInfo) Trackers() [][]string {
+	var tiers [][]string
+	for _, tier := range m.AnnounceList {
+		if len(tier) > 0 {
+			tiers = append(tiers, tier)
+		}
+	}
+	if len(tiers) == 0 && m.Announce != "" {
+		tiers = [][]string{{m.Announce}}
+	}
+	return tiers
+}
+
+// Files returns the files of the torrent, without padding files. The path of
+// the files of single file torrents is the torrent's name.
+func (m *MetaInfo) Files() []File {
+	if len(m.Info.Files) > 0 {
+		var files []File
+		for _, f := range m.Info.Files {
+			if !f.IsPadding() {
+				files = append(files, f)
+			}
+		}
+		return files
+	}
+	if m.IsV2() {
+		var files []File
+		walkFileTree(m.Info.FileTree, nil, &files)
+		return files
+	}
+	return []File{{Length: m.Info.Length, Path: []string{m.Info.Name}}}
+}
+
+// walkFileTree lists the files of a v2 file tree, in path order.
+func walkFileTree(tree map[string]interface{}, path []string, files *[]File) {
+	names := make([]string, 0, len(tree))
+	for name := range tree {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		node, ok := tree[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if leaf, ok := node[""].(map[string]interface{}); ok && name != "" {
+			length, _ := leaf["length"].(int64)
+			root, _ := leaf["pieces root"].(string)
+			*files = append(*files, File{
+				Length:     length,
+				Path:       append(append([]string{}, path...), name),
+				PiecesRoot: []byte(root),
+			})
+			continue
+		}
+		walkFileTree(node, append(append([]string{}, path...), name), files)
+	}
+}
+
+// TotalLength returns the size of the content, without padding files.
+func (m *MetaInfo) TotalLength() int64 {
+	var total int64
+	for _, f := range m.Files() {
+		total += f.Length
+	}
+	return total
+}
+
+// PieceCount returns the number of v1 pieces, or of v2 pieces for v2 only
+// torrents.
+func (m *MetaInfo) PieceCount() int {
+	if m.IsV1() {
+		return len(m.Info.Pieces) / sha1.Size
+	}
+	if m.Info.PieceLength <= 0 {
+		return 0
+	}
+	var count int64
+	for _, f := range m.Files() {
+		count += (f.Length + m.Info.PieceLength - 1) / m.Info.PieceLength
+	}
+	return int(count)
+}
+
+// Validate checks that the torrent is well formed: it has a name and pieces,
+// the piece hashes cover the content, and the file paths stay within the
+// torrent's directory.
+func (m *MetaInfo) Validate() error {
+	info := &m.Info
+	if err := validPathElement(info.Name); err != nil {
+		return fmt.Errorf("invalid name: %w", err)
+	}
+	if info.PieceLength <= 0 {
+		return errors.New("invalid piece length")
+	}
+	if !m.IsV1() && !m.IsV2() {
+		return errors.New("no v1 pieces or v2 file tree")
+	}
+	if m.IsV2() && (info.PieceLength < 16<<10 || info.PieceLength&(info.PieceLength-1) != 0) {
+		return fmt.Errorf("v2 piece length %d is not a power of two of at least 16 KiB", info.PieceLength)
+	}
+	if info.Length != 0 && len(info.Files) > 0 {
+		return errors.New("both length and files are set")
+	}
+	files := append([]File{}, info.Files...)
+	if m.IsV2() {
+		walkFileTree(info.FileTree, nil, &files)
+	}
+	for _, f := range files {
+		if f.Length < 0 {
+			return fmt.Errorf("negative length for %s", strings.Join(f.Path, "/"))
+		}
+		if len(f.Path) == 0 {
+			return errors.New("file without a path")
+		}
+		for _, element := range f.Path {
+			if err := validPathElement(element); err != nil {
+				return fmt.Errorf("invalid path %q: %w", strings.Join(f.Path, "/"), err)
+			}
+		}
+	}
+	if m.IsV1() {
+		if len(info.Pieces)%sha1.Size != 0 {
+			return errors.New("pieces is not a list of SHA-1 hashes")
+		}
+		var total int64
+		if len(info.Files) == 0 {
+			total = info.Length
+		}
+		for _, f := range info.Files {
+			total += f.Length
+		}
+		if want := (total + info.PieceLength - 1) / info.PieceLength; int64(len(info.Pieces)/sha1.Size) != want {
+			return fmt.Errorf("%d pieces for %d bytes, expected %d", len(info.Pieces)/sha1.Size, total, want)
+		}
+	}
+	return nil
+}
+
+func validPathElement(element string) error {
+	switch {
+	case element == "":
+		return errors.New("empty name")
+	case element == "." || element == "..":
+		return fmt.Errorf("%q is not allowed", element)
+	case strings.ContainsAny(element, "/\\\x00"):
+		return fmt.Errorf("%q contains a path separator", element)
+	}
+	return nil
+}
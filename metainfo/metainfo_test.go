@@ -0,0 +1,195 @@
+package metainfo_test
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc/metainfo"
+)
+
+// str bencodes a string.
+func str(s string) string {
+	return strconv.Itoa(len(s)) + ":" + s
+}
+
+// dict bencodes a dictionary of already encoded values, with the keys given
+// in sorted order.
+func dict(pairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('d')
+	for i := 0; i < len(pairs); i += 2 {
+		b.WriteString(str(pairs[i]) + pairs[i+1])
+	}
+	b.WriteByte('e')
+	return b.String()
+}
+
+var (
+	testPieces = string([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19})
+	testRoot   = testPieces + string([]byte{20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31})
+	testTree   = dict("test.txt", dict("", dict("length", "i20e", "pieces root", str(testRoot))))
+)
+
+// The info hashes of these info dictionaries were computed with Python's
+// hashlib over the same bytes.
+var knownTorrents = []struct {
+	name   string
+	info   string
+	v1, v2 string
+	hash   string
+}{
+	{
+		name: "v1",
+		info: dict("length", "i20e", "name", str("test.txt"), "piece length", "i16384e", "pieces", str(testPieces)),
+		v1:   "906cfefa88be4296879bcb8447b4778f0f71fb82",
+		hash: "906cfefa88be4296879bcb8447b4778f0f71fb82",
+	},
+	{
+		name: "hybrid",
+		info: dict("file tree", testTree, "length", "i20e", "meta version", "i2e", "name", str("test.txt"),
+			"piece length", "i16384e", "pieces", str(testPieces)),
+		v1:   "f80710f60aabeb0d6ac1b98438fb359b8128408f",
+		v2:   "0b8fc5436344a1f95980e56af8c7176cc66be4478dc40a4ae9e306119f733220",
+		hash: "f80710f60aabeb0d6ac1b98438fb359b8128408f",
+	},
+	{
+		name: "v2",
+		info: dict("file tree", testTree, "meta version", "i2e", "name", str("test.txt"), "piece length", "i16384e"),
+		v2:   "b6821fe96ce641bf6375a8cd467e36263fd438f3e1b88759ebf0f59181de0918",
+		hash: "b6821fe96ce641bf6375a8cd467e36263fd438f3",
+	},
+}
+
+func TestInfoHashes(t *testing.T) {
+	for _, tt := range knownTorrents {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := metainfo.Parse([]byte(dict("announce", str("http://tracker/announce"), "info", tt.info)))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if err := m.Validate(); err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+			v1, ok := m.InfoHashV1()
+			if got := hex.EncodeToString(v1[:]); ok != (tt.v1 != "") || ok && got != tt.v1 {
+				t.Errorf("InfoHashV1() = %s, %v, want %q", got, ok, tt.v1)
+			}
+			v2, ok := m.InfoHashV2()
+			if got := hex.EncodeToString(v2[:]); ok != (tt.v2 != "") || ok && got != tt.v2 {
+				t.Errorf("InfoHashV2() = %s, %v, want %q", got, ok, tt.v2)
+			}
+			if got := m.HashString(); got != tt.hash {
+				t.Errorf("HashString() = %s, want %s", got, tt.hash)
+			}
+			files := m.Files()
+			if len(files) != 1 || files[0].Length != 20 || !reflect.DeepEqual(files[0].Path, []string{"test.txt"}) {
+				t.Errorf("Files() = %+v, want test.txt of 20 bytes", files)
+			}
+			if m.PieceCount() != 1 || m.TotalLength() != 20 {
+				t.Errorf("got %d pieces of %d bytes, want 1 of 20", m.PieceCount(), m.TotalLength())
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	files := "l" + dict("length", "i5e", "path", "l"+str("a")+str("b.txt")+"e") +
+		dict("attr", str("p"), "length", "i16379e", "path", "l"+str(".pad")+str("16379")+"e") +
+		dict("length", "i100e", "path", "l"+str("c.txt")+"e") + "e"
+	info := dict("files", files, "name", str("dir"), "piece length", "i16384e", "pieces", str(testPieces+testPieces), "private", "i1e")
+	data := dict(
+		"announce", str("http://ignored/announce"),
+		"announce-list", "l"+"l"+str("http://a/announce")+str("http://b/announce")+"e"+"le"+"l"+str("udp://c:80")+"e"+"e",
+		"comment", str("a comment"),
+		"created by", str("test"),
+		"creation date", "i1600000000e",
+		"info", info,
+		"url-list", str("http://seed/"),
+	)
+	m, err := metainfo.Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	if string(m.InfoBytes) != info {
+		t.Errorf("InfoBytes = %q, want the info dictionary as in the file", m.InfoBytes)
+	}
+	if m.Info.Name != "dir" || !m.Info.Private || m.Comment != "a comment" || m.CreatedBy != "test" {
+		t.Errorf("got info %+v, comment %q, created by %q", m.Info, m.Comment, m.CreatedBy)
+	}
+	if !m.CreationDate.Equal(time.Unix(1600000000, 0)) {
+		t.Errorf("CreationDate = %v", m.CreationDate)
+	}
+	wantTrackers := [][]string{{"http://a/announce", "http://b/announce"}, {"udp://c:80"}}
+	if got := m.Trackers(); !reflect.DeepEqual(got, wantTrackers) {
+		t.Errorf("Trackers() = %q, want %q", got, wantTrackers)
+	}
+	if !reflect.DeepEqual(m.WebSeeds, []string{"http://seed/"}) {
+		t.Errorf("WebSeeds = %q", m.WebSeeds)
+	}
+	var paths []string
+	for _, f := range m.Files() {
+		paths = append(paths, strings.Join(f.Path, "/"))
+	}
+	if strings.Join(paths, ",") != "a/b.txt,c.txt" || m.TotalLength() != 105 {
+		t.Errorf("got files %q of %d bytes, want the padding file left out", paths, m.TotalLength())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"not bencoded":  "not a torrent",
+		"no info":       dict("announce", str("http://tracker/announce")),
+		"unsorted info": dict("info", "d4:name1:a6:lengthi1ee"),
+		"bad url-list":  dict("info", knownTorrents[0].info, "url-list", "i1e"),
+		"truncated":     dict("info", knownTorrents[0].info)[:40],
+	} {
+		if _, err := metainfo.Parse([]byte(data)); err == nil {
+			t.Errorf("%s: Parse succeeded", name)
+		}
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	fileList := func(paths ...string) string {
+		list := "l"
+		for _, path := range paths {
+			list += dict("length", "i10e", "path", "l"+path+"e")
+		}
+		return list + "e"
+	}
+	tree := func(name string) string {
+		return dict(name, dict("", dict("length", "i20e", "pieces root", str(testRoot))))
+	}
+	tests := map[string]string{
+		"dot dot name":      dict("length", "i20e", "name", str(".."), "piece length", "i16384e", "pieces", str(testPieces)),
+		"dot dot path":      dict("files", fileList(str("..")+str("etc")+str("passwd")), "name", str("dir"), "piece length", "i16384e", "pieces", str(testPieces)),
+		"dot dot inside":    dict("files", fileList(str("a")+str("..")+str("..")+str("x")), "name", str("dir"), "piece length", "i16384e", "pieces", str(testPieces)),
+		"separator in path": dict("files", fileList(str("../x")), "name", str("dir"), "piece length", "i16384e", "pieces", str(testPieces)),
+		"empty path":        dict("files", fileList(""), "name", str("dir"), "piece length", "i16384e", "pieces", str(testPieces)),
+		"dot dot in tree":   dict("file tree", dict("..", tree("x")), "meta version", "i2e", "name", str("dir"), "piece length", "i16384e"),
+		"empty name":        dict("length", "i20e", "name", str(""), "piece length", "i16384e", "pieces", str(testPieces)),
+		"no pieces":         dict("length", "i20e", "name", str("a"), "piece length", "i16384e"),
+		"missing pieces":    dict("length", "i20000e", "name", str("a"), "piece length", "i16384e", "pieces", str(testPieces)),
+		"partial hash":      dict("length", "i20e", "name", str("a"), "piece length", "i16384e", "pieces", str(testPieces[:19])),
+		"zero piece length": dict("length", "i20e", "name", str("a"), "piece length", "i0e", "pieces", str(testPieces)),
+		"length and files":  dict("files", fileList(str("a")), "length", "i10e", "name", str("dir"), "piece length", "i16384e", "pieces", str(testPieces)),
+		"v2 piece length":   dict("file tree", tree("x"), "meta version", "i2e", "name", str("dir"), "piece length", "i1000e"),
+	}
+	for name, info := range tests {
+		m, err := metainfo.Parse([]byte(dict("info", info)))
+		if err != nil {
+			t.Errorf("%s: Parse: %v", name, err)
+			continue
+		}
+		if err := m.Validate(); err == nil {
+			t.Errorf("%s: Validate succeeded", name)
+		}
+	}
+}
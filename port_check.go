@@ -0,0 +1,42 @@
+package transmission
+
+import (
+	"context"
+)
+
+type IPProtocol string
+
+const (
+	IPv4 IPProtocol = "ipv4"
+	IPv6 IPProtocol = "ipv6"
+)
+
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md#46-port-checking
+type portTestRequestArgs struct {
+	IPProtocol IPProtocol `json:"ip_protocol,omitempty"`
+}
+
+type portTestResponse struct {
+	Result    string               `json:"result"`
+	Arguments portTestResponseArgs `json:"arguments"`
+	Tag       int64                `json:"tag"`
+}
+
+type portTestResponseArgs struct {
+	PortIsOpen bool       `json:"port-is-open"`
+	IPProtocol IPProtocol `json:"ip_protocol"`
+}
+
+// PortTest asks the daemon whether its peer port is reachable from the
+// internet. An empty ipProtocol leaves the choice to the daemon; older daemons
+// only test IPv4.
+func (t *Client) PortTest(ctx context.Context, ipProtocol IPProtocol) (bool, error) {
+	var response portTestResponse
+	req := portTestRequestArgs{
+		IPProtocol: ipProtocol,
+	}
+	if err := t.callRPC(ctx, "port-test", &req, &response); err != nil {
+		return false, err
+	}
+	return response.Arguments.PortIsOpen, nil
+}
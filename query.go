@@ -0,0 +1,652 @@
+package transmission
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query filters, sorts and limits torrent lists on the client side.
+//
+// Filters compare fields with =, !=, <, <=, >, >=, ~ (regular expression
+// match) and !~, and combine them with and, or, not and parentheses:
+//
+//	status=seeding and ratio>2 and label=tv and name~"S01"
+//
+// Fields are named as in the RPC spec, like uploadRatio or peer-limit, with
+// label as an alias of labels and ratio as an alias of uploadRatio. Fields
+// torrent-get doesn't return are refused. Lists of strings match if any
+// element does, other lists compare by length. Numbers take binary size units
+// (700M, 1.5G) and percentages (50%). The status and error fields take names like seeding
+// or tracker-error. Seconds fields like eta take durations (90m, 2d, 1w), and
+// date fields compared with a duration compare the time since the date, so
+// addedDate<7d is true for torrents added in the last week. A field on its own
+// tests a boolean field.
+type Query struct {
+	// Sort orders the results, by the first key then the next ones
+	Sort []SortKey
+	// Limit caps the number of results, 0 for no limit
+	Limit  int
+	match  func(*Torrent, time.Time) bool
+	fields []string
+}
+
+// SortKey orders torrents by a field.
+type SortKey struct {
+	Field      string
+	Descending bool
+	index      []int
+}
+
+// ParseQuery parses a filter. An empty filter matches every torrent.
+func ParseQuery(filter string) (*Query, error) {
+	q := &Query{
+		match: func(*Torrent, time.Time) bool { return true },
+	}
+	tokens, err := lexQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return q, nil
+	}
+	p := &queryParser{tokens: tokens}
+	if q.match, err = p.parseOr(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in query", p.tokens[p.pos].text)
+	}
+	q.fields = p.fields
+	return q, nil
+}
+
+// ParseSortKeys parses a comma separated list of fields, each prefixed with
+// - to sort in descending order, like -ratio,name.
+func ParseSortKeys(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key := SortKey{Field: strings.TrimPrefix(field, "-"), Descending: strings.HasPrefix(field, "-")}
+		index, ok := lookupQueryField(key.Field)
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %q", key.Field)
+		}
+		key.index = index
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Fields returns the RPC names of the torrent fields the filter and sort keys
+// read, to only request those with TorrentFieldsOption.
+func (q *Query) Fields() []string {
+	fields := append([]string{}, q.fields...)
+	for _, key := range q.Sort {
+		if index, ok := lookupQueryField(key.Field); ok {
+			fields = append(fields, rpcFieldName(index))
+		}
+	}
+	return uniqueStrings(fields)
+}
+
+// Match is true if the torrent passes the filter.
+func (q *Query) Match(t *Torrent) bool {
+	return q.match(t, time.Now())
+}
+
+// Apply returns the torrents passing the filter, sorted and limited.
+func (q *Query) Apply(torrents []Torrent) []Torrent {
+	indices := q.apply(len(torrents), func(i int) *Torrent { return &torrents[i] })
+	matched := make([]Torrent, len(indices))
+	for i, index := range indices {
+		matched[i] = torrents[index]
+	}
+	return matched
+}
+
+// ApplyServerTorrents is Apply for the torrents of a Fleet.
+func (q *Query) ApplyServerTorrents(torrents []ServerTorrent) []ServerTorrent {
+	indices := q.apply(len(torrents), func(i int) *Torrent { return &torrents[i].Torrent })
+	matched := make([]ServerTorrent, len(indices))
+	for i, index := range indices {
+		matched[i] = torrents[index]
+	}
+	return matched
+}
+
+// apply returns the indices of the n torrents passing the filter, sorted and
+// limited.
+func (q *Query) apply(n int, torrent func(i int) *Torrent) []int {
+	now := time.Now()
+	matched := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if q.match(torrent(i), now) {
+			matched = append(matched, i)
+		}
+	}
+	if len(q.Sort) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			return q.Less(torrent(matched[i]), torrent(matched[j]))
+		})
+	}
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched
+}
+
+// Less is true if a sorts before b, for sorting lists that aren't []Torrent.
+func (q *Query) Less(a, b *Torrent) bool {
+	for _, key := range q.Sort {
+		index := key.index
+		if index == nil {
+			var ok bool
+			if index, ok = lookupQueryField(key.Field); !ok {
+				continue
+			}
+		}
+		c := compareValues(reflect.ValueOf(a).Elem().FieldByIndex(index), reflect.ValueOf(b).Elem().FieldByIndex(index))
+		if c != 0 {
+			return (c < 0) != key.Descending
+		}
+	}
+	return false
+}
+
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Bool:
+		return compareFloats(boolFloat(a.Bool()), boolFloat(b.Bool()))
+	case reflect.Slice:
+		return compareFloats(float64(a.Len()), float64(b.Len()))
+	}
+	x, _ := numericValue(a)
+	y, _ := numericValue(b)
+	return compareFloats(x, y)
+}
+
+func compareFloats(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func numericValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// unsentTorrentFields are Torrent fields torrent-get never returns, so
+// filtering or sorting on them would silently see zero values.
+var unsentTorrentFields = map[string]bool{"idleSecs": true, "pieceDownloadSpeed": true, "pieceUploadSpeed": true, "ratio": true}
+
+// queryFields maps the lower case RPC and Go names of the Torrent fields to
+// their index. ratio is an alias of uploadRatio, as the daemon doesn't send
+// the ratio field.
+var queryFields = func() map[string][]int {
+	fields := make(map[string][]int)
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fieldIndex := append(append([]int{}, index...), i)
+			if field.Anonymous {
+				walk(field.Type, fieldIndex)
+				continue
+			}
+			if field.PkgPath != "" || unsentTorrentFields[jsonName(field)] {
+				continue
+			}
+			fields[strings.ToLower(field.Name)] = fieldIndex
+			if name := jsonName(field); name != "" && name != "-" {
+				fields[strings.ToLower(name)] = fieldIndex
+			}
+		}
+	}
+	walk(reflect.TypeOf(Torrent{}), nil)
+	fields["label"] = fields["labels"]
+	fields["ratio"] = fields["uploadratio"]
+	return fields
+}()
+
+func lookupQueryField(name string) ([]int, bool) {
+	index, ok := queryFields[strings.ToLower(name)]
+	return index, ok
+}
+
+// rpcFieldName returns the RPC name of the Torrent field at index.
+func rpcFieldName(index []int) string {
+	return jsonName(reflect.TypeOf(Torrent{}).FieldByIndex(index))
+}
+
+// uniqueStrings removes repeated strings, keeping the first of each.
+func uniqueStrings(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	unique := list[:0]
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+var statusNames = map[string]int{
+	"stopped":         StatusStopped,
+	"paused":          StatusStopped,
+	"check-wait":      StatusCheckWait,
+	"queued-verify":   StatusCheckWait,
+	"check":           StatusCheck,
+	"checking":        StatusCheck,
+	"verifying":       StatusCheck,
+	"download-wait":   StatusDownloadWait,
+	"queued-download": StatusDownloadWait,
+	"download":        StatusDownload,
+	"downloading":     StatusDownload,
+	"seed-wait":       StatusSeedWait,
+	"queued-seed":     StatusSeedWait,
+	"seed":            StatusSeed,
+	"seeding":         StatusSeed,
+}
+
+var errorNames = map[string]int{
+	"none":            TorrentErrorNone,
+	"tracker-warning": TorrentErrorTrackerWarning,
+	"tracker-error":   TorrentErrorTrackerError,
+	"local":           TorrentErrorLocal,
+}
+
+// secondsFields hold a number of seconds, and dateFields a unix timestamp.
+var (
+	secondsFields = map[string]bool{"eta": true, "etaidle": true, "secondsdownloading": true, "secondsseeding": true}
+	dateFields    = map[string]bool{"activitydate": true, "addeddate": true, "datecreated": true, "donedate": true, "editdate": true, "startdate": true}
+)
+
+type queryToken struct {
+	text string
+	// quoted tokens are always values, never keywords or operators
+	quoted bool
+	op     bool
+}
+
+var queryOperators = []string{"!=", "!~", "<=", ">=", "==", "=", "<", ">", "~", "(", ")"}
+
+func lexQuery(s string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		if unicode.IsSpace(rune(c)) {
+			i++
+			continue
+		}
+		if c == '"' {
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated string in query")
+			}
+			// Only \" and \\ are escapes, so regular expressions keep their
+			// backslashes
+			text := strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(s[i+1 : end])
+			tokens = append(tokens, queryToken{text: text, quoted: true})
+			i = end + 1
+			continue
+		}
+		matched := false
+		for _, op := range queryOperators {
+			if strings.HasPrefix(s[i:], op) {
+				tokens = append(tokens, queryToken{text: op, op: true})
+				i += len(op)
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		end := i
+		for end < len(s) && !unicode.IsSpace(rune(s[end])) && !strings.ContainsRune("=!<>~()\"", rune(s[end])) {
+			end++
+		}
+		tokens = append(tokens, queryToken{text: s[i:end]})
+		i = end
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	// fields are the RPC names of the fields compared
+	fields []string
+}
+
+type predicate func(*Torrent, time.Time) bool
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) keyword(word string) bool {
+	token, ok := p.peek()
+	if ok && !token.quoted && !token.op && strings.EqualFold(token.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) parseOr() (predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t *Torrent, now time.Time) bool { return l(t, now) || right(t, now) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t *Torrent, now time.Time) bool { return l(t, now) && right(t, now) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (predicate, error) {
+	if p.keyword("not") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(t *Torrent, now time.Time) bool { return !inner(t, now) }, nil
+	}
+	token, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	if token.op && token.text == "(" {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || !closing.op || closing.text != ")" {
+			return nil, fmt.Errorf("missing ) in query")
+		}
+		p.pos++
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (predicate, error) {
+	fieldToken, _ := p.peek()
+	if fieldToken.op || fieldToken.quoted {
+		return nil, fmt.Errorf("expected a field name, got %q", fieldToken.text)
+	}
+	p.pos++
+	index, ok := lookupQueryField(fieldToken.text)
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", fieldToken.text)
+	}
+	p.fields = append(p.fields, rpcFieldName(index))
+	// Aliases and Go names are looked up by the RPC name
+	field := strings.ToLower(rpcFieldName(index))
+	opToken, ok := p.peek()
+	if !ok || !opToken.op || opToken.text == "(" || opToken.text == ")" {
+		// A field on its own tests a boolean
+		if reflect.TypeOf(Torrent{}).FieldByIndex(index).Type.Kind() != reflect.Bool {
+			return nil, fmt.Errorf("field %q is not a boolean, compare it to a value", fieldToken.text)
+		}
+		return func(t *Torrent, _ time.Time) bool {
+			return reflect.ValueOf(t).Elem().FieldByIndex(index).Bool()
+		}, nil
+	}
+	p.pos++
+	valueToken, ok := p.peek()
+	if !ok || valueToken.op {
+		return nil, fmt.Errorf("expected a value after %s%s", fieldToken.text, opToken.text)
+	}
+	p.pos++
+	op := opToken.text
+	if op == "==" {
+		op = "="
+	}
+	return compileComparison(field, index, op, valueToken.text)
+}
+
+func compileComparison(field string, index []int, op, value string) (predicate, error) {
+	fieldType := reflect.TypeOf(Torrent{}).FieldByIndex(index).Type
+	get := func(t *Torrent) reflect.Value {
+		return reflect.ValueOf(t).Elem().FieldByIndex(index)
+	}
+	if op == "~" || op == "!~" {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression for %s: %w", field, err)
+		}
+		negate := op == "!~"
+		switch {
+		case fieldType.Kind() == reflect.String:
+			return func(t *Torrent, _ time.Time) bool {
+				return re.MatchString(get(t).String()) != negate
+			}, nil
+		case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.String:
+			return func(t *Torrent, _ time.Time) bool {
+				return anyString(get(t), re.MatchString) != negate
+			}, nil
+		}
+		return nil, fmt.Errorf("field %s can't be matched with %s", field, op)
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		return func(t *Torrent, _ time.Time) bool {
+			return compareOp(op, strings.Compare(get(t).String(), value))
+		}, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil || (op != "=" && op != "!=") {
+			return nil, fmt.Errorf("field %s takes =true or =false", field)
+		}
+		return func(t *Torrent, _ time.Time) bool {
+			return (get(t).Bool() == b) == (op == "=")
+		}, nil
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.String {
+			if op != "=" && op != "!=" {
+				return nil, fmt.Errorf("field %s takes =, !=, ~ or !~", field)
+			}
+			return func(t *Torrent, _ time.Time) bool {
+				return anyString(get(t), func(s string) bool { return s == value }) == (op == "=")
+			}, nil
+		}
+		n, err := parseQueryNumber(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", field, err)
+		}
+		return func(t *Torrent, _ time.Time) bool {
+			return compareOp(op, compareFloats(float64(get(t).Len()), n))
+		}, nil
+	}
+	if _, ok := numericValue(reflect.Zero(fieldType)); !ok {
+		return nil, fmt.Errorf("field %s can't be filtered", field)
+	}
+	if dateFields[field] {
+		if d, err := parseQueryDuration(value); err == nil {
+			return func(t *Torrent, now time.Time) bool {
+				date, _ := numericValue(get(t))
+				age := now.Sub(time.Unix(int64(date), 0))
+				return compareOp(op, compareFloats(float64(age), float64(d)))
+			}, nil
+		}
+	}
+	n, err := parseFieldNumber(field, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", field, err)
+	}
+	return func(t *Torrent, _ time.Time) bool {
+		x, _ := numericValue(get(t))
+		return compareOp(op, compareFloats(x, n))
+	}, nil
+}
+
+func anyString(list reflect.Value, match func(string) bool) bool {
+	for i := 0; i < list.Len(); i++ {
+		if match(list.Index(i).String()) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareOp(op string, c int) bool {
+	switch op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+func parseFieldNumber(field, value string) (float64, error) {
+	switch field {
+	case "status":
+		if status, ok := statusNames[strings.ToLower(value)]; ok {
+			return float64(status), nil
+		}
+	case "error":
+		if code, ok := errorNames[strings.ToLower(value)]; ok {
+			return float64(code), nil
+		}
+	}
+	if secondsFields[field] {
+		if d, err := parseQueryDuration(value); err == nil {
+			return d.Seconds(), nil
+		}
+	}
+	return parseQueryNumber(value)
+}
+
+var querySizeUnits = map[string]float64{
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+	"T": 1 << 40,
+}
+
+// parseQueryNumber parses a number with an optional binary size unit or a
+// percent sign.
+func parseQueryNumber(value string) (float64, error) {
+	if strings.HasSuffix(value, "%") {
+		n, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", value)
+		}
+		return n / 100, nil
+	}
+	upper := strings.ToUpper(value)
+	upper = strings.TrimSuffix(strings.TrimSuffix(upper, "IB"), "B")
+	unit := 1.0
+	if len(upper) > 0 {
+		if u, ok := querySizeUnits[upper[len(upper)-1:]]; ok {
+			unit = u
+			upper = upper[:len(upper)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(upper, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return n * unit, nil
+}
+
+var queryDurationPart = regexp.MustCompile(`^(\d+(?:\.\d+)?)(s|m|h|d|w)`)
+
+var queryDurationUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// parseQueryDuration parses durations like 90m or 1d12h.
+func parseQueryDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	var total time.Duration
+	for rest := value; rest != ""; {
+		part := queryDurationPart.FindStringSubmatch(rest)
+		if part == nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		n, _ := strconv.ParseFloat(part[1], 64)
+		total += time.Duration(n * float64(queryDurationUnits[part[2]]))
+		rest = rest[len(part[0]):]
+	}
+	return total, nil
+}
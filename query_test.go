@@ -0,0 +1,164 @@
+package transmission_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+func queryTorrent(name string, mutate func(*transmission.Torrent)) transmission.Torrent {
+	var torrent transmission.Torrent
+	torrent.Name = name
+	mutate(&torrent)
+	return torrent
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, filter := range []string{
+		"nosuchfield=1",
+		"idleSecs>10",
+		"pieceDownloadSpeed>0",
+		"name=",
+		"status=seeding and",
+		"(status=seeding",
+		"name=a)",
+		`name~"("`,
+		"totalSize>lots",
+		"totalSize",
+		"isPrivate>true",
+		"labels<tv",
+		`name="unterminated`,
+		"eta>soon",
+	} {
+		if _, err := transmission.ParseQuery(filter); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded", filter)
+		}
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	now := uint64(time.Now().Unix())
+	seeding := queryTorrent("Show.S01E01", func(torrent *transmission.Torrent) {
+		torrent.Status = transmission.StatusSeed
+		torrent.UploadRatio = 3
+		torrent.Labels = []string{"tv", "hd"}
+		torrent.Files = make([]transmission.TorrentFile, 2)
+		torrent.TotalSize = 700 << 20
+		torrent.PercentDone = 1
+		torrent.AddedDate = now - 24*60*60
+		torrent.SecondsSeeding = 3 * 24 * 60 * 60
+	})
+	downloading := queryTorrent("Film (2020)", func(torrent *transmission.Torrent) {
+		torrent.Status = transmission.StatusDownload
+		torrent.UploadRatio = 0.5
+		torrent.IsPrivate = true
+		torrent.TotalSize = 4 << 30
+		torrent.PercentDone = 0.25
+		torrent.AddedDate = now - 30*24*60*60
+		torrent.ETA = 90 * 60
+		torrent.Error = transmission.TorrentErrorTrackerError
+	})
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{seeding.Name, downloading.Name}},
+		{"ratio>2", []string{seeding.Name}},
+		{"uploadRatio<=0.5", []string{downloading.Name}},
+		{"status=seeding", []string{seeding.Name}},
+		{"status!=seeding", []string{downloading.Name}},
+		{"error=tracker-error", []string{downloading.Name}},
+		{"label=tv", []string{seeding.Name}},
+		{"labels!=tv", []string{downloading.Name}},
+		{`label~"^h"`, []string{seeding.Name}},
+		{`name~"S01"`, []string{seeding.Name}},
+		{`name!~"S01"`, []string{downloading.Name}},
+		{`name="Film (2020)"`, []string{downloading.Name}},
+		{"files>1", []string{seeding.Name}},
+		{"totalSize>1G", []string{downloading.Name}},
+		{"totalSize=700M", []string{seeding.Name}},
+		{"percentDone<50%", []string{downloading.Name}},
+		{"isPrivate", []string{downloading.Name}},
+		{"not isPrivate", []string{seeding.Name}},
+		{"isPrivate=false", []string{seeding.Name}},
+		{"eta>1h and eta<=90m", []string{downloading.Name}},
+		{"secondsSeeding>=2d", []string{seeding.Name}},
+		{"addedDate<7d", []string{seeding.Name}},
+		{"addedDate>1w", []string{downloading.Name}},
+		{"status=seeding or isPrivate", []string{seeding.Name, downloading.Name}},
+		{"not (status=seeding or isPrivate)", nil},
+		{"STATUS==Seeding AND Ratio>2", []string{seeding.Name}},
+	}
+	torrents := []transmission.Torrent{seeding, downloading}
+	for _, tt := range tests {
+		query, err := transmission.ParseQuery(tt.filter)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.filter, err)
+			continue
+		}
+		var got []string
+		for _, torrent := range query.Apply(torrents) {
+			got = append(got, torrent.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q matched %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestQuerySortAndLimit(t *testing.T) {
+	torrents := []transmission.Torrent{
+		queryTorrent("b", func(torrent *transmission.Torrent) { torrent.UploadRatio = 1 }),
+		queryTorrent("a", func(torrent *transmission.Torrent) { torrent.UploadRatio = 3 }),
+		queryTorrent("c", func(torrent *transmission.Torrent) { torrent.UploadRatio = 1 }),
+	}
+	tests := []struct {
+		sort  string
+		limit int
+		want  string
+	}{
+		{"name", 0, "a,b,c"},
+		{"-name", 0, "c,b,a"},
+		{"-ratio,name", 0, "a,b,c"},
+		{"ratio,-name", 0, "c,b,a"},
+		{"-uploadRatio", 2, "a,b"},
+		{"", 1, "b"},
+	}
+	for _, tt := range tests {
+		query, err := transmission.ParseQuery("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query.Sort, err = transmission.ParseSortKeys(tt.sort); err != nil {
+			t.Fatalf("ParseSortKeys(%q): %v", tt.sort, err)
+		}
+		query.Limit = tt.limit
+		var names []string
+		for _, torrent := range query.Apply(torrents) {
+			names = append(names, torrent.Name)
+		}
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("sort %q limit %d gave %s, want %s", tt.sort, tt.limit, got, tt.want)
+		}
+	}
+	if _, err := transmission.ParseSortKeys("name,nosuchfield"); err == nil {
+		t.Error("ParseSortKeys accepted an unknown field")
+	}
+}
+
+func TestQueryFields(t *testing.T) {
+	query, err := transmission.ParseQuery("label=tv and ratio>2 and Status=seeding and ratio<5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Sort, err = transmission.ParseSortKeys("-addedDate,name"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"labels", "uploadRatio", "status", "addedDate", "name"}
+	if got := query.Fields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package transmission
+
+import (
+	"context"
+)
+
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md#46-queue-movement-requests
+
+// QueueMoveTop moves the given torrents to the front of the queue.
+func (t *Client) QueueMoveTop(ctx context.Context, ids ...TorrentID) error {
+	return t.torrentAction(ctx, "queue-move-top", ids)
+}
+
+// QueueMoveUp moves the given torrents one position towards the front of the queue.
+func (t *Client) QueueMoveUp(ctx context.Context, ids ...TorrentID) error {
+	return t.torrentAction(ctx, "queue-move-up", ids)
+}
+
+// QueueMoveDown moves the given torrents one position towards the back of the queue.
+func (t *Client) QueueMoveDown(ctx context.Context, ids ...TorrentID) error {
+	return t.torrentAction(ctx, "queue-move-down", ids)
+}
+
+// QueueMoveBottom moves the given torrents to the back of the queue.
+func (t *Client) QueueMoveBottom(ctx context.Context, ids ...TorrentID) error {
+	return t.torrentAction(ctx, "queue-move-bottom", ids)
+}
@@ -0,0 +1,25 @@
+package transmission
+
+import (
+	"context"
+)
+
+// GetRecentlyActiveTorrents returns the torrents that changed recently, and
+// the IDs of the torrents removed recently.
+func (t *Client) GetRecentlyActiveTorrents(ctx context.Context, opts ...TorrentsOption) ([]Torrent, []int, error) {
+	var response listTorrentsResponse
+	req := listTorrentsRequestArgs{
+		Fields: torrentFields,
+	}
+	if t.tableFormat {
+		req.Format = tableFormat
+	}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	req.IDs = torrentIDs{RecentlyActive}
+	if err := t.callRPC(ctx, "torrent-get", &req, &response); err != nil {
+		return nil, nil, err
+	}
+	return []Torrent(response.Arguments.Torrents), response.Arguments.Removed, nil
+}
@@ -0,0 +1,154 @@
+package transmission_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/transmissiontest"
+)
+
+// The fixtures in testdata are synthetic: they were written by hand from the
+// RPC specs of Transmission 3.00 (rpc-version 16) and 4.0.6 (rpc-version 17),
+// and recorded through the Recorder against a stub server, not a real daemon.
+// They check the client decodes responses shaped like the spec describes,
+// not that it works against any particular release.
+var replayFixtures = []struct {
+	path       string
+	rpcVersion float64
+	version    string
+	fileCount  int
+	trackers   string
+}{
+	{"testdata/synthetic-rpc16-legacy.json", 16, "3.00 (bb6b5a062e)", 0, ""},
+	{"testdata/synthetic-rpc17-legacy.json", 17, "4.0.6 (38c164933e)", 1, "http://bttracker.debian.org:6969/announce\n"},
+}
+
+func replayClient(t *testing.T, path string) (*transmission.Client, *transmissiontest.Replayer) {
+	t.Helper()
+	replayer, err := transmissiontest.LoadReplayer(path)
+	if err != nil {
+		t.Fatalf("LoadReplayer: %v", err)
+	}
+	client, err := transmission.New(context.Background(), "http://localhost:9091", transmission.TransportOption(replayer))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return client, replayer
+}
+
+// TestReplay replays the calls of a client connecting, getting the session
+// and listing the torrents.
+func TestReplay(t *testing.T) {
+	for _, fixture := range replayFixtures {
+		t.Run(fixture.version, func(t *testing.T) {
+			client, replayer := replayClient(t, fixture.path)
+			if client.Protocol() != transmission.ProtocolLegacy {
+				t.Errorf("detected protocol %v, want legacy", client.Protocol())
+			}
+			if client.DownloadDir != "/var/lib/transmission-daemon/downloads" {
+				t.Errorf("download dir %q from the session", client.DownloadDir)
+			}
+			session, err := client.GetSession(context.Background())
+			if err != nil {
+				t.Fatalf("GetSession: %v", err)
+			}
+			if session["rpc-version"] != fixture.rpcVersion || session["version"] != fixture.version {
+				t.Errorf("got rpc-version %v, version %v", session["rpc-version"], session["version"])
+			}
+			torrents, err := client.GetTorrents(context.Background())
+			if err != nil {
+				t.Fatalf("GetTorrents: %v", err)
+			}
+			if len(torrents) != 2 {
+				t.Fatalf("got %d torrents, want 2", len(torrents))
+			}
+
+			seeding := torrents[0]
+			if seeding.Name != "debian-11.5.0-amd64-netinst.iso" || seeding.HashString != "d55be2cd263efa84aeb9495333a4fabc428a4250" {
+				t.Errorf("got torrent %q, hash %s", seeding.Name, seeding.HashString)
+			}
+			if seeding.Status != transmission.StatusSeed || seeding.PercentDone != 1 || seeding.IsStalled {
+				t.Errorf("got status %d, done %v, stalled %v, want a seeding torrent", seeding.Status, seeding.PercentDone, seeding.IsStalled)
+			}
+			if seeding.TotalSize != 400556032 || seeding.PieceCount != 1528 || seeding.PieceSize != 262144 {
+				t.Errorf("got size %d, %d pieces of %d", seeding.TotalSize, seeding.PieceCount, seeding.PieceSize)
+			}
+			if len(seeding.Files) != 1 || len(seeding.FileStats) != 1 || !seeding.FileStats[0].Wanted {
+				t.Errorf("got files %+v, stats %+v", seeding.Files, seeding.FileStats)
+			}
+			if len(seeding.Peers) != 1 || seeding.Peers[0].RateToPeer != 131072 {
+				t.Errorf("got peers %+v", seeding.Peers)
+			}
+			if len(seeding.Labels) != 1 || seeding.Labels[0] != "linux" {
+				t.Errorf("got labels %q", seeding.Labels)
+			}
+			if seeding.UploadRatio != 1.7337 || seeding.ETA != -1 {
+				t.Errorf("got ratio %v, eta %d", seeding.UploadRatio, seeding.ETA)
+			}
+			if seeding.FileCount != fixture.fileCount || seeding.TrackerList != fixture.trackers {
+				t.Errorf("got file count %d, tracker list %q", seeding.FileCount, seeding.TrackerList)
+			}
+			magnet, err := seeding.Magnet()
+			if err != nil || magnet.InfoHash != seeding.HashString {
+				t.Errorf("magnet link parsed to %+v, %v", magnet, err)
+			}
+
+			waiting := torrents[1]
+			if waiting.MetadataPercentComplete != 0 || waiting.Status != transmission.StatusStopped || len(waiting.Files) != 0 {
+				t.Errorf("got metadata %v, status %d, %d files, want a stopped magnet without metadata",
+					waiting.MetadataPercentComplete, waiting.Status, len(waiting.Files))
+			}
+			if waiting.UploadRatio != -1 || waiting.QueuePosition != 1 {
+				t.Errorf("got ratio %v, queue position %d", waiting.UploadRatio, waiting.QueuePosition)
+			}
+
+			if unused := replayer.Unused(); len(unused) != 0 {
+				t.Errorf("%d recorded calls weren't replayed", len(unused))
+			}
+		})
+	}
+}
+
+// TestRecordOverUnixSocket records through the socket transport, which
+// TransportOption would replace.
+func TestRecordOverUnixSocket(t *testing.T) {
+	server := transmissiontest.NewServer()
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "transmission")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "rpc.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("no Unix sockets: %v", err)
+	}
+	go http.Serve(listener, httputil.NewSingleHostReverseProxy(target))
+	defer listener.Close()
+
+	recorder := transmissiontest.NewRecorder(nil)
+	client, err := transmission.New(context.Background(), "unix://"+socketPath, transmission.WrapTransportOption(recorder.Wrap))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := client.GetSession(context.Background()); err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	calls := recorder.Fixture().Interactions
+	if len(calls) == 0 || calls[len(calls)-1].Method != "session-get" {
+		t.Errorf("recorded %+v, want the calls ending with session-get", calls)
+	}
+}
@@ -2,7 +2,6 @@ package transmission
 
 import (
 	"context"
-	"fmt"
 )
 
 type sessionStatsResponse struct {
@@ -36,8 +35,5 @@ func (t *Client) GetSessionStats(ctx context.Context) (*Session, error) {
 	if err := t.callRPC(ctx, "session-stats", nil, &response); err != nil {
 		return nil, err
 	}
-	if response.Result != "success" {
-		return nil, fmt.Errorf("failed to get session stats: %s", response.Result)
-	}
 	return &response.Arguments, nil
 }
@@ -0,0 +1,29 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+)
+
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md#36-moving-a-torrent
+type setLocationRequestArgs struct {
+	IDs      torrentIDs `json:"ids"`
+	Location string     `json:"location"`
+	Move     bool       `json:"move"`
+}
+
+// SetTorrentLocation changes the download directory of the given torrents. If
+// move is set the daemon moves their data there, otherwise it looks for the
+// data in the new location. At least one ID is required.
+func (t *Client) SetTorrentLocation(ctx context.Context, location string, move bool, ids ...TorrentID) error {
+	if len(ids) == 0 {
+		return errors.New("no torrents to move")
+	}
+	var response genericResponse
+	req := setLocationRequestArgs{
+		IDs:      ids,
+		Location: location,
+		Move:     move,
+	}
+	return t.callRPC(ctx, "torrent-set-location", &req, &response)
+}
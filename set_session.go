@@ -0,0 +1,12 @@
+package transmission
+
+import (
+	"context"
+)
+
+// SetSession changes session settings, keyed by their names in session-get.
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md#411-mutators
+func (t *Client) SetSession(ctx context.Context, settings map[string]interface{}) error {
+	var response genericResponse
+	return t.callRPC(ctx, "session-set", settings, &response)
+}
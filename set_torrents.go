@@ -0,0 +1,169 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+)
+
+// File priorities used by PriorityOption and TorrentFileStats.Priority.
+const (
+	PriorityLow    = -1
+	PriorityNormal = 0
+	PriorityHigh   = 1
+)
+
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md#32-torrent-mutator-torrent-set
+type setTorrentsRequestArgs map[string]interface{}
+
+type SetTorrentsOption func(setTorrentsRequestArgs)
+
+func BandwidthPriorityOption(priority int) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["bandwidthPriority"] = priority
+	}
+}
+
+// DownloadLimitOption limits the download speed of the torrents, in kB/s.
+func DownloadLimitOption(limit int) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["downloadLimit"] = limit
+		req["downloadLimited"] = true
+	}
+}
+
+func NoDownloadLimitOption() SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["downloadLimited"] = false
+	}
+}
+
+// UploadLimitOption limits the upload speed of the torrents, in kB/s.
+func UploadLimitOption(limit int) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["uploadLimit"] = limit
+		req["uploadLimited"] = true
+	}
+}
+
+func NoUploadLimitOption() SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["uploadLimited"] = false
+	}
+}
+
+// FilesWantedOption marks the files at the given indices of Torrent.Files
+// for download. The daemon treats an empty list as every file, which is also
+// true of the other file options.
+func FilesWantedOption(indices ...int) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["files-wanted"] = nonNilInts(indices)
+	}
+}
+
+// FilesUnwantedOption marks the files at the given indices of Torrent.Files
+// to be skipped.
+func FilesUnwantedOption(indices ...int) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["files-unwanted"] = nonNilInts(indices)
+	}
+}
+
+// PriorityOption sets the priority of the files at the given indices of
+// Torrent.Files to PriorityLow, PriorityNormal or PriorityHigh.
+func PriorityOption(priority int, indices ...int) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		switch {
+		case priority < PriorityNormal:
+			req["priority-low"] = nonNilInts(indices)
+		case priority > PriorityNormal:
+			req["priority-high"] = nonNilInts(indices)
+		default:
+			req["priority-normal"] = nonNilInts(indices)
+		}
+	}
+}
+
+// GroupOption moves the torrents into the named bandwidth group.
+func GroupOption(group string) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["group"] = group
+	}
+}
+
+func HonorsSessionLimitsOption(honors bool) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["honorsSessionLimits"] = honors
+	}
+}
+
+// LabelsOption replaces the labels of the torrents. Passing no labels clears them.
+func LabelsOption(labels ...string) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		if labels == nil {
+			labels = []string{}
+		}
+		req["labels"] = labels
+	}
+}
+
+func PeerLimitOption(limit int) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["peer-limit"] = limit
+	}
+}
+
+func QueuePositionOption(position int) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["queuePosition"] = position
+	}
+}
+
+// SeedIdleLimitOption stops seeding the torrents after they have been idle
+// for the given number of minutes.
+func SeedIdleLimitOption(minutes int) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["seedIdleLimit"] = minutes
+		req["seedIdleMode"] = 1
+	}
+}
+
+// SeedRatioLimitOption stops seeding the torrents once they reach the ratio.
+func SeedRatioLimitOption(ratio float64) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["seedRatioLimit"] = ratio
+		req["seedRatioMode"] = 1
+	}
+}
+
+// TrackerListOption replaces the trackers of the torrents. The list has one
+// announce URL per line, with a blank line between tiers.
+func TrackerListOption(trackerList string) SetTorrentsOption {
+	return func(req setTorrentsRequestArgs) {
+		req["trackerList"] = trackerList
+	}
+}
+
+func nonNilInts(values []int) []int {
+	if values == nil {
+		return []int{}
+	}
+	return values
+}
+
+// SetTorrents changes the settings of the given torrents. At least one ID is
+// required, as an empty list would change every torrent; pass AllTorrents to
+// do that on purpose.
+func (t *Client) SetTorrents(ctx context.Context, ids []TorrentID, opts ...SetTorrentsOption) error {
+	if len(ids) == 0 {
+		return errors.New("no torrents to set")
+	}
+	var response genericResponse
+	req := setTorrentsRequestArgs{}
+	for _, opt := range opts {
+		opt(req)
+	}
+	if selected := selectedIDs(ids); selected != nil {
+		req["ids"] = selected
+	}
+	return t.callRPC(ctx, "torrent-set", req, &response)
+}
@@ -0,0 +1,44 @@
+package transmission_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+func TestSetTorrentsNeedsIDs(t *testing.T) {
+	client, server := newTestClient(t)
+	server.AddTorrent(transmission.Torrent{})
+	sent := len(server.Requests())
+	if err := client.SetTorrents(context.Background(), nil, transmission.DownloadLimitOption(100)); err == nil {
+		t.Error("SetTorrents with no IDs succeeded")
+	}
+	if len(server.Requests()) != sent {
+		t.Error("SetTorrents with no IDs sent a request")
+	}
+	err := client.SetTorrents(context.Background(), []transmission.TorrentID{transmission.AllTorrents, transmission.ID(1)}, transmission.DownloadLimitOption(100))
+	if err == nil {
+		t.Error("SetTorrents combining AllTorrents with an ID succeeded")
+	}
+}
+
+func TestSetTorrentsAllTorrents(t *testing.T) {
+	client, server := newTestClient(t)
+	for i := 0; i < 3; i++ {
+		server.AddTorrent(transmission.Torrent{})
+	}
+	ctx := context.Background()
+	if err := client.SetTorrents(ctx, []transmission.TorrentID{transmission.AllTorrents}, transmission.DownloadLimitOption(100)); err != nil {
+		t.Fatalf("SetTorrents: %v", err)
+	}
+	torrents, err := client.GetTorrents(ctx)
+	if err != nil {
+		t.Fatalf("GetTorrents: %v", err)
+	}
+	for _, torrent := range torrents {
+		if !torrent.DownloadLimited || torrent.DownloadLimit != 100 {
+			t.Errorf("torrent %d has download limit %d, limited %v", torrent.ID, torrent.DownloadLimit, torrent.DownloadLimited)
+		}
+	}
+}
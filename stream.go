@@ -0,0 +1,142 @@
+package transmission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// streamRPC performs a call whose response is decoded while it is read. The
+// decoder is handed to decodeArguments positioned at the start of the
+// arguments of the response, or the result of a JSON-RPC response, which it
+// must consume completely.
+func (t *Client) streamRPC(ctx context.Context, requestMethod string, requestArguments interface{}, decodeArguments func(*json.Decoder) error) error {
+	return t.traceCall(ctx, requestMethod, func(tag int64) error {
+		body, err := t.encodeRequest(requestMethod, tag, requestArguments)
+		if err != nil {
+			return err
+		}
+		resp, err := t.post(ctx, body)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		decoder := json.NewDecoder(resp.Body)
+		return t.decodeEnvelope(decoder, tag, decodeArguments)
+	})
+}
+
+// decodeEnvelope walks the top level object of a response. Transmission sends
+// the arguments before the result and tag, so those are only checked once the
+// arguments have been consumed.
+func (t *Client) decodeEnvelope(decoder *json.Decoder, tag int64, decodeArguments func(*json.Decoder) error) error {
+	argumentsKey := "arguments"
+	if t.protocol == ProtocolJSONRPC {
+		argumentsKey = "result"
+	}
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	var result string
+	var responseTag *int64
+	var rpcErr *RPCError
+	for decoder.More() {
+		key, err := decodeKey(decoder)
+		if err != nil {
+			return err
+		}
+		switch {
+		case key == argumentsKey:
+			err = decodeArguments(decoder)
+		case key == "result":
+			err = decoder.Decode(&result)
+		case key == "tag" || key == "id":
+			err = decoder.Decode(&responseTag)
+		case key == "error":
+			err = decoder.Decode(&rpcErr)
+		default:
+			err = skipValue(decoder)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return err
+	}
+	if rpcErr != nil {
+		return rpcErr
+	}
+	if err := checkTag(responseTag, tag); err != nil {
+		return err
+	}
+	if t.protocol != ProtocolJSONRPC && result != "success" {
+		return &RPCError{Message: result}
+	}
+	return nil
+}
+
+// unmarshalValue decodes a value of a streamed response into v, converting
+// snake_case keys first when the client speaks JSON-RPC.
+func (t *Client) unmarshalValue(data []byte, v interface{}) error {
+	if t.protocol != ProtocolJSONRPC {
+		return json.Unmarshal(data, v)
+	}
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(legacyKeys(generic, reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, v)
+}
+
+// expectObject consumes the start of an object, returning false if the value
+// is null instead.
+func expectObject(decoder *json.Decoder) (bool, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return false, err
+	}
+	if token == nil {
+		return false, nil
+	}
+	if token != json.Delim('{') {
+		return false, fmt.Errorf("expected { in response, got %v", token)
+	}
+	return true, nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v in response, got %v", delim, token)
+	}
+	return nil
+}
+
+func decodeKey(decoder *json.Decoder) (string, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("expected object key in response, got %v", token)
+	}
+	return key, nil
+}
+
+func skipValue(decoder *json.Decoder) error {
+	var skipped json.RawMessage
+	return decoder.Decode(&skipped)
+}
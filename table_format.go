@@ -0,0 +1,122 @@
+package transmission
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+const tableFormat = "table"
+
+// minTableFormatRPCVersion is the rpc-version of Transmission 3.00, the first
+// release to support the table format of torrent-get.
+const minTableFormatRPCVersion = 16
+
+// TableFormatOption requests torrent-get responses in table format, which
+// sends the field names once instead of for every torrent. It is ignored by
+// daemons that don't support it.
+func TableFormatOption() ClientOption {
+	return func(c *Client) {
+		c.tableFormat = true
+	}
+}
+
+// torrentList decodes the torrents of a torrent-get response in either
+// object or table format.
+type torrentList []Torrent
+
+func (l *torrentList) UnmarshalJSON(data []byte) error {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+	if len(elements) == 0 || !isJSONArray(elements[0]) {
+		var torrents []Torrent
+		if err := json.Unmarshal(data, &torrents); err != nil {
+			return err
+		}
+		*l = torrents
+		return nil
+	}
+	var header []string
+	if err := json.Unmarshal(elements[0], &header); err != nil {
+		return err
+	}
+	torrents := make([]Torrent, len(elements)-1)
+	for i, row := range elements[1:] {
+		object, err := tableRowObject(header, row)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(object, &torrents[i]); err != nil {
+			return err
+		}
+	}
+	*l = torrents
+	return nil
+}
+
+// tableRowObject turns a row of a table format response into an object keyed
+// by the names in header.
+func tableRowObject(header []string, row json.RawMessage) (json.RawMessage, error) {
+	var values []json.RawMessage
+	if err := json.Unmarshal(row, &values); err != nil {
+		return nil, err
+	}
+	object := new(bytes.Buffer)
+	object.WriteByte('{')
+	for i, value := range values {
+		if i >= len(header) {
+			break
+		}
+		if i > 0 {
+			object.WriteByte(',')
+		}
+		key, err := json.Marshal(header[i])
+		if err != nil {
+			return nil, err
+		}
+		object.Write(key)
+		object.WriteByte(':')
+		object.Write(value)
+	}
+	object.WriteByte('}')
+	return object.Bytes(), nil
+}
+
+// tableObjects turns a decoded table format list into a list of objects, or
+// returns false if the list isn't in table format.
+func tableObjects(elements []interface{}) ([]interface{}, bool) {
+	if len(elements) == 0 {
+		return nil, false
+	}
+	first, ok := elements[0].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	header := make([]string, len(first))
+	for i, name := range first {
+		if header[i], ok = name.(string); !ok {
+			return nil, false
+		}
+	}
+	objects := make([]interface{}, 0, len(elements)-1)
+	for _, element := range elements[1:] {
+		row, ok := element.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		object := make(map[string]interface{}, len(header))
+		for i, value := range row {
+			if i < len(header) {
+				object[header[i]] = value
+			}
+		}
+		objects = append(objects, object)
+	}
+	return objects, true
+}
+
+func isJSONArray(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '['
+}
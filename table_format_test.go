@@ -0,0 +1,125 @@
+package transmission_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/transmissiontest"
+)
+
+func TestTorrentListUnmarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		names []string
+		ids   []int
+		err   bool
+	}{
+		{name: "objects", data: `[{"id":1,"name":"a"},{"id":2,"name":"b"}]`, ids: []int{1, 2}, names: []string{"a", "b"}},
+		{name: "table", data: `[["id","name"],[1,"a"],[2,"b"]]`, ids: []int{1, 2}, names: []string{"a", "b"}},
+		{name: "empty", data: `[]`},
+		{name: "header only", data: `[["id","name"]]`},
+		// Missing values leave their fields unset, extra values are dropped
+		{name: "short row", data: `[["id","name"],[1]]`, ids: []int{1}, names: []string{""}},
+		{name: "long row", data: `[["id"],[1,"a"]]`, ids: []int{1}, names: []string{""}},
+		{name: "header not strings", data: `[[1,2],[1,"a"]]`, err: true},
+		{name: "row not a list", data: `[["id","name"],{"id":1}]`, err: true},
+		{name: "not a list", data: `{"id":1}`, err: true},
+	}
+	for _, tt := range tests {
+		var list transmission.TorrentList
+		err := json.Unmarshal([]byte(tt.data), &list)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: got error %v, want error %t", tt.name, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		var ids []int
+		var names []string
+		for _, torrent := range list {
+			ids = append(ids, torrent.ID)
+			names = append(names, torrent.Name)
+		}
+		if !reflect.DeepEqual(ids, tt.ids) || !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("%s: got ids %v and names %q, want %v and %q", tt.name, ids, names, tt.ids, tt.names)
+		}
+	}
+}
+
+// torrentGetFormats returns the format of every torrent-get the server got.
+func torrentGetFormats(t *testing.T, server *transmissiontest.Server) []string {
+	t.Helper()
+	var formats []string
+	for _, request := range server.Requests() {
+		if request.Method != "torrent-get" {
+			continue
+		}
+		var arguments struct {
+			Format string `json:"format"`
+		}
+		if err := json.Unmarshal(request.Arguments, &arguments); err != nil {
+			t.Fatal(err)
+		}
+		formats = append(formats, arguments.Format)
+	}
+	return formats
+}
+
+func TestTableFormatOption(t *testing.T) {
+	server := transmissiontest.NewServer()
+	defer server.Close()
+	addTorrents(server, 3)
+	ctx := context.Background()
+	client, err := transmission.New(ctx, server.URL, transmission.TableFormatOption())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	torrents, err := client.GetTorrents(ctx)
+	if err != nil {
+		t.Fatalf("GetTorrents: %v", err)
+	}
+	var iterated []transmission.Torrent
+	err = client.IterateTorrents(ctx, func(torrent transmission.Torrent) error {
+		iterated = append(iterated, torrent)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateTorrents: %v", err)
+	}
+	if want := server.Torrents(); len(torrents) != len(want) || len(iterated) != len(want) {
+		t.Fatalf("got %d and %d torrents, want %d", len(torrents), len(iterated), len(want))
+	}
+	for i, torrent := range torrents {
+		if torrent.Name != iterated[i].Name || len(torrent.Files) != 4 || len(iterated[i].Files) != 4 {
+			t.Errorf("torrent %d decoded as %q with %d files and %q with %d files", i, torrent.Name, len(torrent.Files), iterated[i].Name, len(iterated[i].Files))
+		}
+	}
+	if formats := torrentGetFormats(t, server); strings.Join(formats, ",") != "table,table" {
+		t.Errorf("got formats %q, want table for both calls", formats)
+	}
+}
+
+func TestTableFormatNeedsRPCVersion(t *testing.T) {
+	server := transmissiontest.NewServer()
+	defer server.Close()
+	addTorrents(server, 1)
+	// Transmission 2.94, before the table format
+	server.SetSession(map[string]interface{}{"rpc-version": 15})
+	ctx := context.Background()
+	client, err := transmission.New(ctx, server.URL, transmission.TableFormatOption())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if torrents, err := client.GetTorrents(ctx); err != nil || len(torrents) != 1 {
+		t.Fatalf("GetTorrents returned %d torrents, %v", len(torrents), err)
+	}
+	if formats := torrentGetFormats(t, server); len(formats) != 1 || formats[0] != "" {
+		t.Errorf("got formats %q, want objects below rpc-version 16", formats)
+	}
+}
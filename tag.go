@@ -0,0 +1,71 @@
+package transmission
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// RequestError wraps the failure of an RPC call with the method and tag of
+// the request, so it can be matched against the daemon's logs.
+type RequestError struct {
+	Method string
+	Tag    int64
+	Err    error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s (tag %d): %v", e.Method, e.Tag, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// RequestTrace describes a finished RPC call.
+type RequestTrace struct {
+	Method   string
+	Tag      int64
+	Start    time.Time
+	Duration time.Duration
+	// Err is nil if the call succeeded
+	Err error
+}
+
+// TraceOption registers a hook that is called after every RPC call, for
+// logging or tracing.
+func TraceOption(trace func(context.Context, RequestTrace)) ClientOption {
+	return func(c *Client) {
+		c.trace = trace
+	}
+}
+
+// newTagPrefix returns a random prefix that keeps the tags of different
+// clients apart. It is kept to 20 bits so tags stay below 2^53.
+func newTagPrefix() int64 {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return time.Now().UnixNano() & 0xfffff
+	}
+	return int64(binary.BigEndian.Uint32(b[:]) & 0xfffff)
+}
+
+// nextTag returns a tag that is unique across requests of this client, and
+// very likely across clients.
+func (t *Client) nextTag() int64 {
+	count := atomic.AddInt64(&t.requestCount, 1)
+	return t.tagPrefix<<32 | count&0xffffffff
+}
+
+func checkTag(got *int64, want int64) error {
+	if got == nil {
+		return fmt.Errorf("response is missing tag %d", want)
+	}
+	if *got != want {
+		return fmt.Errorf("response tag %d does not match request tag %d", *got, want)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package transmission_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+// tagServer answers every call with an empty session, and with the tag or
+// id returned by respond for the tag or id of the request, or none if it
+// returns nil.
+type tagServer struct {
+	mu      sync.Mutex
+	respond func(tag int64) interface{}
+	result  string
+}
+
+func (s *tagServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Transmission-Session-Id") == "" {
+		w.Header().Set("X-Transmission-Session-Id", "session")
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	var request struct {
+		JSONRPC string `json:"jsonrpc"`
+		Tag     int64  `json:"tag"`
+		ID      int64  `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	respond, result := s.respond, s.result
+	s.mu.Unlock()
+	arguments := map[string]interface{}{"rpc-version": 17, "download-dir": "/downloads"}
+	if request.JSONRPC != "" {
+		response := map[string]interface{}{"jsonrpc": "2.0", "result": arguments}
+		if id := respond(request.ID); id != nil {
+			response["id"] = id
+		}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	response := map[string]interface{}{"result": result, "arguments": arguments}
+	if tag := respond(request.Tag); tag != nil {
+		response["tag"] = tag
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *tagServer) set(respond func(tag int64) interface{}, result string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.respond, s.result = respond, result
+}
+
+func echoTag(tag int64) interface{} {
+	return tag
+}
+
+func TestResponseTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(tag int64) interface{}
+		want    string
+	}{
+		{"mismatch", func(tag int64) interface{} { return tag + 1 }, "does not match"},
+		{"missing", func(int64) interface{} { return nil }, "missing tag"},
+	}
+	for _, protocol := range []transmission.Protocol{transmission.ProtocolLegacy, transmission.ProtocolJSONRPC} {
+		for _, tt := range tests {
+			t.Run(protocol.String()+" "+tt.name, func(t *testing.T) {
+				handler := &tagServer{respond: echoTag, result: "success"}
+				server := httptest.NewServer(handler)
+				defer server.Close()
+				client, err := transmission.New(context.Background(), server.URL, transmission.ProtocolOption(protocol))
+				if err != nil {
+					t.Fatalf("New: %v", err)
+				}
+				handler.set(tt.respond, "success")
+				_, err = client.GetSession(context.Background())
+				var requestErr *transmission.RequestError
+				if !errors.As(err, &requestErr) || requestErr.Method != "session-get" || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("GetSession returned %v, want a session-get error containing %q", err, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestLegacyResponseResult(t *testing.T) {
+	handler := &tagServer{respond: echoTag, result: "success"}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	client, err := transmission.New(context.Background(), server.URL, transmission.ProtocolOption(transmission.ProtocolLegacy))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if client.DownloadDir != "/downloads" {
+		t.Errorf("DownloadDir = %q, want the arguments of session-get decoded", client.DownloadDir)
+	}
+	handler.set(echoTag, "no such torrent")
+	_, err = client.GetSession(context.Background())
+	var rpcErr *transmission.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Message != "no such torrent" {
+		t.Errorf("GetSession returned %v, want the result as an RPCError", err)
+	}
+}
+
+func TestTraceOption(t *testing.T) {
+	var mu sync.Mutex
+	var traces []transmission.RequestTrace
+	server := &tagServer{respond: echoTag, result: "success"}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	client, err := transmission.New(context.Background(), httpServer.URL,
+		transmission.ProtocolOption(transmission.ProtocolLegacy),
+		transmission.TraceOption(func(ctx context.Context, trace transmission.RequestTrace) {
+			mu.Lock()
+			defer mu.Unlock()
+			traces = append(traces, trace)
+		}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	server.set(func(tag int64) interface{} { return tag + 1 }, "success")
+	callErr := client.StopTorrents(context.Background(), transmission.ID(1))
+	if callErr == nil {
+		t.Fatal("StopTorrents succeeded with a mismatched tag")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// New gets the session, then the failed call
+	if len(traces) != 2 {
+		t.Fatalf("got %d traces, want 2", len(traces))
+	}
+	first, second := traces[0], traces[1]
+	if first.Method != "session-get" || first.Err != nil {
+		t.Errorf("first trace %+v, want a successful session-get", first)
+	}
+	if second.Method != "torrent-stop" || second.Err != callErr || second.Tag == first.Tag {
+		t.Errorf("second trace %+v, want the failed torrent-stop with a new tag", second)
+	}
+	var requestErr *transmission.RequestError
+	if !errors.As(callErr, &requestErr) || requestErr.Tag != second.Tag {
+		t.Errorf("call returned %v, want a RequestError with tag %d", callErr, second.Tag)
+	}
+	if second.Start.Before(first.Start) || second.Duration <= 0 {
+		t.Errorf("second trace started %v after %v, took %v", second.Start, first.Start, second.Duration)
+	}
+}
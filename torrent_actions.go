@@ -0,0 +1,68 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md#31-torrent-action-requests
+type torrentActionRequestArgs struct {
+	IDs torrentIDs `json:"ids,omitempty"`
+}
+
+type removeTorrentsRequestArgs struct {
+	IDs             torrentIDs `json:"ids"`
+	DeleteLocalData bool       `json:"delete-local-data"`
+}
+
+// torrentAction calls method on the given torrents. At least one ID is
+// required, as the daemon acts on every torrent without any; pass
+// AllTorrents to do that on purpose.
+func (t *Client) torrentAction(ctx context.Context, method string, ids []TorrentID) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("no torrents for %s, pass AllTorrents to select every torrent", method)
+	}
+	var response genericResponse
+	req := torrentActionRequestArgs{
+		IDs: selectedIDs(ids),
+	}
+	return t.callRPC(ctx, method, &req, &response)
+}
+
+// StartTorrents starts the given torrents, or every torrent with AllTorrents.
+func (t *Client) StartTorrents(ctx context.Context, ids ...TorrentID) error {
+	return t.torrentAction(ctx, "torrent-start", ids)
+}
+
+// StopTorrents stops the given torrents, or every torrent with AllTorrents.
+func (t *Client) StopTorrents(ctx context.Context, ids ...TorrentID) error {
+	return t.torrentAction(ctx, "torrent-stop", ids)
+}
+
+// VerifyTorrents verifies the local data of the given torrents, or of every
+// torrent with AllTorrents.
+func (t *Client) VerifyTorrents(ctx context.Context, ids ...TorrentID) error {
+	return t.torrentAction(ctx, "torrent-verify", ids)
+}
+
+// ReannounceTorrents asks the trackers of the given torrents for more peers,
+// or of every torrent with AllTorrents.
+func (t *Client) ReannounceTorrents(ctx context.Context, ids ...TorrentID) error {
+	return t.torrentAction(ctx, "torrent-reannounce", ids)
+}
+
+// RemoveTorrents removes the given torrents, and their data if
+// deleteLocalData is set. At least one ID is required, and AllTorrents is
+// refused: every torrent can't be removed in one call.
+func (t *Client) RemoveTorrents(ctx context.Context, deleteLocalData bool, ids ...TorrentID) error {
+	if len(ids) == 0 {
+		return errors.New("no torrents to remove")
+	}
+	var response genericResponse
+	req := removeTorrentsRequestArgs{
+		IDs:             ids,
+		DeleteLocalData: deleteLocalData,
+	}
+	return t.callRPC(ctx, "torrent-remove", &req, &response)
+}
@@ -0,0 +1,61 @@
+package transmission_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+func TestTorrentActionsNeedIDs(t *testing.T) {
+	client, server := newTestClient(t)
+	server.AddTorrent(transmission.Torrent{})
+	ctx := context.Background()
+	actions := map[string]func(ids ...transmission.TorrentID) error{
+		"start":        func(ids ...transmission.TorrentID) error { return client.StartTorrents(ctx, ids...) },
+		"stop":         func(ids ...transmission.TorrentID) error { return client.StopTorrents(ctx, ids...) },
+		"verify":       func(ids ...transmission.TorrentID) error { return client.VerifyTorrents(ctx, ids...) },
+		"reannounce":   func(ids ...transmission.TorrentID) error { return client.ReannounceTorrents(ctx, ids...) },
+		"queue top":    func(ids ...transmission.TorrentID) error { return client.QueueMoveTop(ctx, ids...) },
+		"queue up":     func(ids ...transmission.TorrentID) error { return client.QueueMoveUp(ctx, ids...) },
+		"queue down":   func(ids ...transmission.TorrentID) error { return client.QueueMoveDown(ctx, ids...) },
+		"queue bottom": func(ids ...transmission.TorrentID) error { return client.QueueMoveBottom(ctx, ids...) },
+		"remove":       func(ids ...transmission.TorrentID) error { return client.RemoveTorrents(ctx, false, ids...) },
+	}
+	sent := len(server.Requests())
+	for name, action := range actions {
+		if err := action(); err == nil {
+			t.Errorf("%s with no IDs succeeded", name)
+		}
+		if err := action(transmission.AllTorrents, transmission.ID(1)); err == nil {
+			t.Errorf("%s combining AllTorrents with an ID succeeded", name)
+		}
+	}
+	if len(server.Requests()) != sent {
+		t.Error("actions with no IDs sent a request")
+	}
+	if err := client.RemoveTorrents(ctx, false, transmission.AllTorrents); err == nil {
+		t.Error("RemoveTorrents with AllTorrents succeeded")
+	}
+	if len(server.Torrents()) != 1 {
+		t.Error("the torrent was removed")
+	}
+}
+
+func TestStopAllTorrents(t *testing.T) {
+	client, server := newTestClient(t)
+	for i := 0; i < 3; i++ {
+		var torrent transmission.Torrent
+		torrent.Status = transmission.StatusDownload
+		server.AddTorrent(torrent)
+	}
+	ctx := context.Background()
+	if err := client.StopTorrents(ctx, transmission.AllTorrents); err != nil {
+		t.Fatalf("StopTorrents: %v", err)
+	}
+	for _, torrent := range server.Torrents() {
+		if torrent.Status != transmission.StatusStopped {
+			t.Errorf("torrent %d has status %d after stopping every torrent", torrent.ID, torrent.Status)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package transmission
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	recentlyActive = "recently-active"
+	allTorrents    = "all"
+)
+
+// TorrentID identifies a torrent by its numeric ID, which changes every time
+// the daemon restarts, or by its info hash, which doesn't.
+//
+// The methods selecting torrents used to take numeric IDs as ints. Wrap them
+// with ID or IDs, like StopTorrents(ctx, IDs(ids...)...), or use
+// GetTorrentsByID for lists.
+type TorrentID struct {
+	id   int
+	hash string
+}
+
+// RecentlyActive selects the torrents that changed recently. It can't be
+// combined with other IDs.
+var RecentlyActive = TorrentID{hash: recentlyActive}
+
+// AllTorrents selects every torrent, for the methods that otherwise refuse
+// an empty list of IDs. It can't be combined with other IDs.
+var AllTorrents = TorrentID{hash: allTorrents}
+
+// ID identifies a torrent by its numeric ID.
+func ID(id int) TorrentID {
+	return TorrentID{id: id}
+}
+
+// IDs identifies torrents by their numeric IDs.
+func IDs(ids ...int) []TorrentID {
+	torrentIDs := make([]TorrentID, len(ids))
+	for i, id := range ids {
+		torrentIDs[i] = ID(id)
+	}
+	return torrentIDs
+}
+
+// Hash identifies a torrent by its hex encoded info hash, either a 40
+// character SHA1 hash or a 64 character v2 hash.
+func Hash(hash string) TorrentID {
+	return TorrentID{hash: strings.ToLower(hash)}
+}
+
+// ParseTorrentID parses a numeric ID, a hex encoded info hash or
+// "recently-active".
+func ParseTorrentID(s string) (TorrentID, error) {
+	if s == recentlyActive {
+		return RecentlyActive, nil
+	}
+	if id, err := strconv.Atoi(s); err == nil {
+		return ID(id), nil
+	}
+	if !IsHashString(s) {
+		return TorrentID{}, fmt.Errorf("invalid torrent ID %q: must be a number or a 40 or 64 character info hash", s)
+	}
+	return Hash(s), nil
+}
+
+// IsHashString reports whether s is a hex encoded SHA1 or v2 info hash.
+func IsHashString(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// IsHash reports whether the torrent is identified by its info hash.
+func (i TorrentID) IsHash() bool {
+	return i.hash != "" && i.hash != recentlyActive && i != AllTorrents
+}
+
+func (i TorrentID) String() string {
+	if i.hash != "" {
+		return i.hash
+	}
+	return strconv.Itoa(i.id)
+}
+
+func (i TorrentID) MarshalJSON() ([]byte, error) {
+	if i.hash != "" {
+		return json.Marshal(i.hash)
+	}
+	return json.Marshal(i.id)
+}
+
+// torrentIDs is the ids argument of torrent requests, which is a single
+// string rather than a list when selecting recently active torrents.
+type torrentIDs []TorrentID
+
+// selectedIDs returns the ids argument selecting ids, which is left out for
+// AllTorrents.
+func selectedIDs(ids []TorrentID) torrentIDs {
+	if len(ids) == 1 && ids[0] == AllTorrents {
+		return nil
+	}
+	return ids
+}
+
+func (ids torrentIDs) MarshalJSON() ([]byte, error) {
+	for _, id := range ids {
+		if id == AllTorrents {
+			// Selecting every torrent leaves out the ids argument
+			return nil, errors.New("all torrents can't be selected here")
+		}
+		if id != RecentlyActive {
+			continue
+		}
+		if len(ids) > 1 {
+			return nil, errors.New("recently-active can't be combined with other torrent IDs")
+		}
+		return json.Marshal(recentlyActive)
+	}
+	return json.Marshal([]TorrentID(ids))
+}
@@ -0,0 +1,82 @@
+package transmission_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+const (
+	sha1Hash = "0123456789abcdef0123456789abcdef01234567"
+	v2Hash   = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+)
+
+func TestParseTorrentID(t *testing.T) {
+	tests := []struct {
+		in   string
+		want transmission.TorrentID
+		json string
+		err  bool
+	}{
+		{in: "42", want: transmission.ID(42), json: `42`},
+		{in: "recently-active", want: transmission.RecentlyActive, json: `"recently-active"`},
+		{in: sha1Hash, want: transmission.Hash(sha1Hash), json: `"` + sha1Hash + `"`},
+		{in: v2Hash, want: transmission.Hash(v2Hash), json: `"` + v2Hash + `"`},
+		// Hashes are compared lower case
+		{in: "0123456789ABCDEF0123456789ABCDEF01234567", want: transmission.Hash(sha1Hash), json: `"` + sha1Hash + `"`},
+		{in: "", err: true},
+		{in: "all", err: true},
+		{in: "0123456789abcdef", err: true},
+		{in: "g123456789abcdef0123456789abcdef01234567", err: true},
+	}
+	for _, tt := range tests {
+		id, err := transmission.ParseTorrentID(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseTorrentID(%q) returned error %v, want error %t", tt.in, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if id != tt.want {
+			t.Errorf("ParseTorrentID(%q) = %v, want %v", tt.in, id, tt.want)
+		}
+		data, err := json.Marshal(id)
+		if err != nil || string(data) != tt.json {
+			t.Errorf("ParseTorrentID(%q) encodes as %s, %v, want %s", tt.in, data, err, tt.json)
+		}
+	}
+}
+
+func TestIsHashString(t *testing.T) {
+	tests := map[string]bool{
+		sha1Hash: true,
+		v2Hash:   true,
+		"0123456789ABCDEF0123456789ABCDEF01234567": true,
+		"": false,
+		"0123456789abcdef0123456789abcdef0123456":   false,
+		"0123456789abcdef0123456789abcdef012345678": false,
+		"0123456789abcdef0123456789abcdef0123456z":  false,
+		"recently-active":                           false,
+	}
+	for in, want := range tests {
+		if got := transmission.IsHashString(in); got != want {
+			t.Errorf("IsHashString(%q) = %t, want %t", in, got, want)
+		}
+	}
+}
+
+func TestGetTorrentsByID(t *testing.T) {
+	client, server := newTestClient(t)
+	addTorrents(server, 3)
+	ctx := context.Background()
+	torrents, err := client.GetTorrentsByID(ctx, server.Torrents()[1].ID)
+	if err != nil || len(torrents) != 1 || torrents[0].Name != "torrent 1" {
+		t.Errorf("got %d torrents, %v, want torrent 1", len(torrents), err)
+	}
+	if torrents, err := client.GetTorrentsByID(ctx); err != nil || len(torrents) != 3 {
+		t.Errorf("got %d torrents, %v without IDs, want every torrent", len(torrents), err)
+	}
+}
@@ -0,0 +1,21 @@
+package transmission
+
+// Values of Torrent.Error
+const (
+	TorrentErrorNone           = 0
+	TorrentErrorTrackerWarning = 1
+	TorrentErrorTrackerError   = 2
+	// TorrentErrorLocal is a local problem, like a full disk, that stops the torrent
+	TorrentErrorLocal = 3
+)
+
+// Values of Torrent.Status
+const (
+	StatusStopped      = 0
+	StatusCheckWait    = 1
+	StatusCheck        = 2
+	StatusDownloadWait = 3
+	StatusDownload     = 4
+	StatusSeedWait     = 5
+	StatusSeed         = 6
+)
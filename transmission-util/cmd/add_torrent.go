@@ -4,25 +4,93 @@ Copyright © 2022 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/bobcob7/transmission-rpc"
 	"github.com/spf13/cobra"
 )
 
+var addWait bool
+var addTimeout time.Duration
+var addTrackers []string
+var addLabels []string
+
 // sessionStatsCmd represents the sessionStats command
 var addTorrentsCmd = &cobra.Command{
-	Use:   "torrent",
-	Short: "Add torrent information from transmission server",
+	Use:         "torrent",
+	Annotations: map[string]string{fleetAnnotation: ""},
+	Short:       "Add torrent information from transmission server",
+	Long: `Add torrent information from transmission server.
+
+With --wait the torrent is stopped as soon as its metadata has downloaded,
+and started again once the file selection flags have been applied.
+
+With --all-servers the torrent is added to the server with the fewest active
+torrents, among the servers sharing one of its --label values if any --server
+has labels.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			return fmt.Errorf("Missing magnet link")
 		}
 		magnetLink := args[0]
-		id, err := tr.AddMagnetLink(cmd.Context(), magnetLink)
+		var addOpts []transmission.AddMagnetLinkOption
+		if len(addTrackers) > 0 {
+			addOpts = append(addOpts, transmission.AddTrackersOption(addTrackers...))
+		}
+		if len(addLabels) > 0 {
+			addOpts = append(addOpts, transmission.AddLabelsOption(addLabels...))
+		}
+		if fleet != nil {
+			if addWait {
+				return fmt.Errorf("--wait can't be used with --all-servers")
+			}
+			server, id, err := fleet.AddMagnetLink(cmd.Context(), magnetLink, addOpts...)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to add torrent:", err)
+				exit(1)
+			}
+			fmt.Printf("Add torrent: %d on %s\n", id, server)
+			return nil
+		}
+		if !addWait {
+			id, err := tr.AddMagnetLink(cmd.Context(), magnetLink, addOpts...)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to add torrent:", err)
+				exit(1)
+			}
+			fmt.Println("Add torrent:", id)
+			return nil
+		}
+		rules, err := fileRules()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), addTimeout)
+		defer cancel()
+		// Every file is unwanted until apply selects some, without rules
+		// AddAndWait wants them all again
+		var apply func(context.Context, *transmission.Torrent) error
+		if len(rules) > 0 {
+			apply = func(ctx context.Context, torrent *transmission.Torrent) error {
+				_, err := tr.SelectFiles(ctx, transmission.ID(torrent.ID), rules...)
+				return err
+			}
+		}
+		progress := transmission.ProgressOption(func(torrent *transmission.Torrent) {
+			fmt.Fprintf(os.Stderr, "Metadata: %.0f%%\n", torrent.MetadataPercentComplete*100)
+		})
+		id, err := tr.AddAndWait(ctx, magnetLink, addOpts, apply, progress)
+		if errors.Is(err, transmission.ErrDuplicateTorrent) {
+			fmt.Println("Torrent already added, left as is:", id)
+			return nil
+		}
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Failed to add torrent:", err)
-			os.Exit(1)
+			exit(1)
 		}
 		fmt.Println("Add torrent:", id)
 		return nil
@@ -31,4 +99,11 @@ var addTorrentsCmd = &cobra.Command{
 
 func init() {
 	addCmd.AddCommand(addTorrentsCmd)
+	addTorrentsCmd.Flags().BoolVar(&addWait, "wait", false, "Wait for the metadata and apply the file selection before starting")
+	addTorrentsCmd.Flags().StringArrayVar(&addTrackers, "tracker", nil, "Add this tracker to the magnet link, can be repeated")
+	addTorrentsCmd.Flags().StringArrayVar(&addLabels, "label", nil, "Label the torrent, can be repeated")
+	addTorrentsCmd.Flags().DurationVar(&addTimeout, "timeout", 10*time.Minute, "How long to wait for the metadata")
+	addTorrentsCmd.Flags().StringArrayVar(&filesInclude, "include", nil, "With --wait, only download files matching this glob")
+	addTorrentsCmd.Flags().StringArrayVar(&filesExclude, "exclude", nil, "With --wait, skip files matching this glob")
+	addTorrentsCmd.Flags().StringVar(&filesMaxSize, "max-size", "", "With --wait, skip files larger than this, like 4G")
 }
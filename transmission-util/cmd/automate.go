@@ -0,0 +1,85 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/bobcob7/transmission-rpc/automation"
+	"github.com/spf13/cobra"
+)
+
+var automateDryRun bool
+var automateOnce bool
+var automateAuditLog string
+
+// automateCmd represents the automate command
+var automateCmd = &cobra.Command{
+	Use:   "automate <rules.json>",
+	Short: "Run housekeeping rules against the torrents of transmission server",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := automation.LoadConfig(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to load rules:", err)
+			exit(1)
+		}
+		interval, err := config.IntervalDuration()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to load rules:", err)
+			exit(1)
+		}
+		var audit io.Writer = os.Stdout
+		if automateAuditLog != "" {
+			f, err := os.OpenFile(automateAuditLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to open audit log:", err)
+				exit(1)
+			}
+			defer f.Close()
+			audit = f
+		}
+		opts := []automation.Option{
+			automation.AuditLogOption(audit),
+			automation.RunErrorOption(func(err error) {
+				fmt.Fprintln(os.Stderr, "Failed to run rules:", err)
+			}),
+		}
+		if automateDryRun {
+			opts = append(opts, automation.DryRunOption())
+		}
+		engine, err := automation.NewEngine(tr, config.Rules, opts...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid rules:", err)
+			exit(1)
+		}
+		if automateOnce {
+			if _, err := engine.RunOnce(cmd.Context()); err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to run rules:", err)
+				exit(1)
+			}
+			return
+		}
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		go func() {
+			<-interrupt
+			cancel()
+		}()
+		engine.Run(ctx, interval)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(automateCmd)
+	automateCmd.Flags().BoolVar(&automateDryRun, "dry-run", false, "Log the actions without taking them")
+	automateCmd.Flags().BoolVar(&automateOnce, "once", false, "Run the rules once and exit")
+	automateCmd.Flags().StringVar(&automateAuditLog, "audit-log", "", "Append the audit log to this file instead of printing it")
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/spf13/cobra"
+)
+
+type blocklistUpdate struct {
+	RuleCount int `json:"ruleCount"`
+}
+
+// blocklistCmd represents the blocklist command
+var blocklistCmd = &cobra.Command{
+	Use:   "blocklist",
+	Short: "Manage the blocklist of transmission server",
+}
+
+// blocklistUpdateCmd represents the blocklist update command
+var blocklistUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the blocklist from its URL",
+	Run: func(cmd *cobra.Command, args []string) {
+		ruleCount, err := tr.UpdateBlocklist(cmd.Context())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to update blocklist:", err)
+			exit(1)
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(blocklistUpdate{RuleCount: ruleCount}); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to marshal output:", err)
+			exit(1)
+		}
+	},
+}
+
+// blocklistStatusCmd represents the blocklist status command
+var blocklistStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Get the blocklist settings",
+	Run: func(cmd *cobra.Command, args []string) {
+		settings, err := tr.GetBlocklistSettings(cmd.Context())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to get blocklist settings:", err)
+			exit(1)
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(settings); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to marshal output:", err)
+			exit(1)
+		}
+	},
+}
+
+var blocklistListenAddr string
+var blocklistAdvertiseHost string
+
+// blocklistPushCmd represents the blocklist push command
+var blocklistPushCmd = &cobra.Command{
+	Use:   "push <file>",
+	Short: "Load a local P2P format blocklist into transmission server",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("Missing blocklist file")
+		}
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		var opts []transmission.PushBlocklistOption
+		if blocklistListenAddr != "" {
+			opts = append(opts, transmission.BlocklistListenAddrOption(blocklistListenAddr))
+		}
+		if blocklistAdvertiseHost != "" {
+			opts = append(opts, transmission.BlocklistAdvertiseHostOption(blocklistAdvertiseHost))
+		}
+		ruleCount, err := tr.PushBlocklist(cmd.Context(), file, opts...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to push blocklist:", err)
+			exit(1)
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(blocklistUpdate{RuleCount: ruleCount}); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to marshal output:", err)
+			exit(1)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(blocklistCmd)
+	blocklistCmd.AddCommand(blocklistUpdateCmd)
+	blocklistCmd.AddCommand(blocklistStatusCmd)
+	blocklistCmd.AddCommand(blocklistPushCmd)
+	blocklistPushCmd.Flags().StringVar(&blocklistListenAddr, "listen", "", "Address to serve the blocklist on, by default a random port on the address that reaches the server, :0 for every interface")
+	blocklistPushCmd.Flags().StringVar(&blocklistAdvertiseHost, "advertise-host", "", "Host the server downloads the blocklist from, detected by default")
+}
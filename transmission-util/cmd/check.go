@@ -0,0 +1,18 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the health of the transmission server",
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+}
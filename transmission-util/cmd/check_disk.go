@@ -0,0 +1,53 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+type diskStatus struct {
+	Path       string `json:"path"`
+	FreeBytes  int64  `json:"freeBytes"`
+	TotalBytes int64  `json:"totalBytes"`
+}
+
+// checkDiskCmd represents the check disk command
+var checkDiskCmd = &cobra.Command{
+	Use:   "disk [path]",
+	Short: "Get the free space of a directory on the transmission server",
+	Long:  "Get the free space of a directory on the transmission server, the download directory by default",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("Too many arguments")
+		}
+		path := tr.DownloadDir
+		if len(args) == 1 {
+			path = args[0]
+		}
+		free, total, err := tr.FreeSpace(cmd.Context(), path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to get free space:", err)
+			exit(1)
+		}
+		status := diskStatus{
+			Path:       path,
+			FreeBytes:  free,
+			TotalBytes: total,
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(status); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to marshal output:", err)
+			exit(1)
+		}
+		return nil
+	},
+}
+
+func init() {
+	checkCmd.AddCommand(checkDiskCmd)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/spf13/cobra"
+)
+
+type portStatus struct {
+	Open bool `json:"open"`
+}
+
+var ipProtocol string
+
+// checkPortCmd represents the check port command
+var checkPortCmd = &cobra.Command{
+	Use:   "port",
+	Short: "Check whether the peer port of the transmission server is open",
+	Long:  "Check whether the peer port of the transmission server is open. Exits with status 2 if it is closed.",
+	Run: func(cmd *cobra.Command, args []string) {
+		open, err := tr.PortTest(cmd.Context(), transmission.IPProtocol(ipProtocol))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to test port:", err)
+			exit(1)
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(portStatus{Open: open}); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to marshal output:", err)
+			exit(1)
+		}
+		if !open {
+			exit(2)
+		}
+	},
+}
+
+func init() {
+	checkCmd.AddCommand(checkPortCmd)
+	checkPortCmd.Flags().StringVar(&ipProtocol, "ip-protocol", "", "IP protocol to test, ipv4 or ipv6")
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/metainfo"
+	"github.com/spf13/cobra"
+)
+
+var createTrackers []string
+var createWebSeeds []string
+var createPrivate bool
+var createComment string
+var createPieceSize string
+var createWorkers int
+var createOutput string
+var createAdd bool
+var createDownloadDir string
+
+// createCmd represents the create command
+var createCmd = &cobra.Command{
+	Use:   "create <path>",
+	Short: "Create a .torrent file from a local file or directory",
+	Long: `Create a .torrent file from a local file or directory, hashing the pieces in
+parallel. The file is written next to the content unless --output is given.
+
+With --add the torrent is also added to the server, downloading into the
+directory holding the content so the server verifies it and starts seeding.
+Use --download-dir when the server sees that directory under another path.`,
+	Args: cobra.ExactArgs(1),
+	// Creating works offline, the server is only connected to for --add
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if !createAdd {
+			return nil
+		}
+		return rootCmd.PersistentPreRunE(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid path:", err)
+			exit(1)
+		}
+		opts := []metainfo.CreateOption{
+			metainfo.TrackersOption(createTrackers...),
+			metainfo.WebSeedsOption(createWebSeeds...),
+			metainfo.CommentOption(createComment),
+			metainfo.CreatedByOption("transmission-util"),
+		}
+		if createPrivate {
+			opts = append(opts, metainfo.PrivateOption())
+		}
+		if createWorkers > 0 {
+			opts = append(opts, metainfo.WorkersOption(createWorkers))
+		}
+		if createPieceSize != "" {
+			pieceSize, err := parseSize(createPieceSize)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Invalid piece size:", err)
+				exit(1)
+			}
+			opts = append(opts, metainfo.PieceLengthOption(pieceSize))
+		}
+		torrent, err := metainfo.Create(cmd.Context(), path, opts...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to create torrent:", err)
+			exit(1)
+		}
+		data, err := torrent.Bytes()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to encode torrent:", err)
+			exit(1)
+		}
+		output := createOutput
+		if output == "" {
+			output = path + ".torrent"
+		}
+		if err := ioutil.WriteFile(output, data, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to write torrent:", err)
+			exit(1)
+		}
+		fmt.Printf("Created %s: %s, %d pieces of %d bytes\n", output, torrent.HashString(), torrent.PieceCount(), torrent.Info.PieceLength)
+		if !createAdd {
+			return
+		}
+		downloadDir := createDownloadDir
+		if downloadDir == "" {
+			downloadDir = filepath.Dir(path)
+		}
+		id, err := tr.AddTorrentFile(cmd.Context(), data, transmission.DownloadDirOption(downloadDir))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to add torrent:", err)
+			exit(1)
+		}
+		fmt.Println("Add torrent:", id)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(createCmd)
+	createCmd.Flags().StringArrayVar(&createTrackers, "tracker", nil, "Announce URL, can be repeated")
+	createCmd.Flags().StringArrayVar(&createWebSeeds, "web-seed", nil, "Web seed URL, can be repeated")
+	createCmd.Flags().BoolVar(&createPrivate, "private", false, "Mark the torrent private")
+	createCmd.Flags().StringVar(&createComment, "comment", "", "Comment stored in the torrent")
+	createCmd.Flags().StringVar(&createPieceSize, "piece-size", "", "Piece size, a power of two like 256K, picked from the content size by default")
+	createCmd.Flags().IntVar(&createWorkers, "workers", 0, "How many pieces to hash at once, the number of CPUs by default")
+	createCmd.Flags().StringVarP(&createOutput, "output", "o", "", "Where to write the .torrent file, <path>.torrent by default")
+	createCmd.Flags().BoolVar(&createAdd, "add", false, "Add the torrent to the server to seed the content")
+	createCmd.Flags().StringVar(&createDownloadDir, "download-dir", "", "With --add, the directory holding the content as the server sees it")
+}
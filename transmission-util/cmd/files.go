@@ -0,0 +1,99 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/spf13/cobra"
+)
+
+// filesCmd represents the files command
+var filesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "Manage the files of torrents",
+}
+
+var filesInclude []string
+var filesExclude []string
+var filesIncludeRegexp []string
+var filesExcludeRegexp []string
+var filesMinSize string
+var filesMaxSize string
+
+// fileRules builds the file rules from the files select flags.
+func fileRules() ([]transmission.FileRule, error) {
+	var rules []transmission.FileRule
+	for _, pattern := range filesInclude {
+		rules = append(rules, transmission.Include(pattern))
+	}
+	for _, pattern := range filesExclude {
+		rules = append(rules, transmission.Exclude(pattern))
+	}
+	for _, expr := range filesIncludeRegexp {
+		rules = append(rules, transmission.IncludeRegexp(expr))
+	}
+	for _, expr := range filesExcludeRegexp {
+		rules = append(rules, transmission.ExcludeRegexp(expr))
+	}
+	if filesMinSize != "" {
+		size, err := parseSize(filesMinSize)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, transmission.MinSize(size))
+	}
+	if filesMaxSize != "" {
+		size, err := parseSize(filesMaxSize)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, transmission.MaxSize(size))
+	}
+	return rules, nil
+}
+
+// filesSelectCmd represents the files select command
+var filesSelectCmd = &cobra.Command{
+	Use:   "select <id|hash|hash-prefix|hash:prefix>",
+	Short: "Choose which files of a torrent to download",
+	Long:  "Choose which files of a torrent to download.\n\n" + torrentIDsHelp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("Missing torrent")
+		}
+		ids, err := parseTorrentIDs(cmd.Context(), args)
+		if err != nil {
+			return err
+		}
+		rules, err := fileRules()
+		if err != nil {
+			return err
+		}
+		selection, err := tr.SelectFiles(cmd.Context(), ids[0], rules...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to select files:", err)
+			exit(1)
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(selection); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to marshal output:", err)
+			exit(1)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filesCmd)
+	filesCmd.AddCommand(filesSelectCmd)
+	filesSelectCmd.Flags().StringArrayVar(&filesInclude, "include", nil, "Only download files matching this glob")
+	filesSelectCmd.Flags().StringArrayVar(&filesExclude, "exclude", nil, "Skip files matching this glob")
+	filesSelectCmd.Flags().StringArrayVar(&filesIncludeRegexp, "include-regexp", nil, "Only download files whose path matches this regular expression")
+	filesSelectCmd.Flags().StringArrayVar(&filesExcludeRegexp, "exclude-regexp", nil, "Skip files whose path matches this regular expression")
+	filesSelectCmd.Flags().StringVar(&filesMinSize, "min-size", "", "Skip files smaller than this, like 10M")
+	filesSelectCmd.Flags().StringVar(&filesMaxSize, "max-size", "", "Skip files larger than this, like 4G")
+}
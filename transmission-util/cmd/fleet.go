@@ -0,0 +1,119 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/spf13/cobra"
+)
+
+var servers []string
+var allServers bool
+var useServer string
+var fleet *transmission.Fleet
+
+// fleetAnnotation marks the commands that run across the fleet with
+// --all-servers. The others act on a single server, so they refuse the flag
+// rather than silently acting on the first server.
+const fleetAnnotation = "fleet"
+
+// supportsFleet reports whether cmd runs across the fleet with --all-servers.
+func supportsFleet(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[fleetAnnotation]
+	return ok
+}
+
+// parseServer splits a --server value of the form name=url, or
+// name:label,label=url to give the server labels for adding torrents.
+func parseServer(value string) (transmission.FleetMember, string, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return transmission.FleetMember{}, "", fmt.Errorf("invalid server %q, expected name=url or name:label,label=url", value)
+	}
+	member := transmission.FleetMember{Name: parts[0]}
+	if i := strings.IndexByte(parts[0], ':'); i >= 0 {
+		member.Name = parts[0][:i]
+		for _, label := range strings.Split(parts[0][i+1:], ",") {
+			if label != "" {
+				member.Labels = append(member.Labels, label)
+			}
+		}
+		if member.Name == "" {
+			return transmission.FleetMember{}, "", fmt.Errorf("invalid server %q, the name is empty", value)
+		}
+	}
+	return member, parts[1], nil
+}
+
+// pickServer returns the name and URL of the --server named by --use-server,
+// or else the first one with it as a label. Without --use-server it is the
+// first --server.
+func pickServer() (string, string, error) {
+	var labelled transmission.FleetMember
+	var labelledURL string
+	for _, server := range servers {
+		member, url, err := parseServer(server)
+		if err != nil {
+			return "", "", err
+		}
+		if useServer == "" || member.Name == useServer {
+			return member.Name, url, nil
+		}
+		if labelledURL == "" && hasLabel(member.Labels, useServer) {
+			labelled, labelledURL = member, url
+		}
+	}
+	if labelledURL == "" {
+		return "", "", fmt.Errorf("no --server is named or labelled %q", useServer)
+	}
+	return labelled.Name, labelledURL, nil
+}
+
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// connectFleet connects to every --server. Servers that can't be reached are
+// reported and left out. When servers have labels, torrents are added to a
+// server sharing one of their labels.
+func connectFleet(ctx context.Context, opts []transmission.ClientOption) (*transmission.Fleet, *transmission.Client, error) {
+	var members []transmission.FleetMember
+	var first *transmission.Client
+	labelled := false
+	for _, server := range servers {
+		member, url, err := parseServer(server)
+		if err != nil {
+			return nil, nil, err
+		}
+		client, err := transmission.New(ctx, url, opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to connect to server %s: %v\n", member.Name, err)
+			continue
+		}
+		if first == nil {
+			first = client
+		}
+		member.Client = client
+		labelled = labelled || len(member.Labels) > 0
+		members = append(members, member)
+	}
+	if len(members) == 0 {
+		return nil, nil, fmt.Errorf("no server could be reached")
+	}
+	var fleetOpts []transmission.FleetOption
+	if labelled {
+		fleetOpts = append(fleetOpts, transmission.AddPolicyOption(transmission.LabelAffinity(transmission.LeastLoaded)))
+	}
+	return transmission.NewFleet(members, fleetOpts...), first, nil
+}
@@ -19,11 +19,11 @@ var sessionStatsCmd = &cobra.Command{
 		session, err := tr.GetSession(cmd.Context())
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Failed to get session:", err)
-			os.Exit(1)
+			exit(1)
 		}
 		if err := json.NewEncoder(os.Stdout).Encode(session); err != nil {
 			fmt.Fprintln(os.Stderr, "Failed to marshal output:", err)
-			os.Exit(1)
+			exit(1)
 		}
 	},
 }
@@ -4,40 +4,88 @@ Copyright © 2022 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
 
+	"github.com/bobcob7/transmission-rpc"
 	"github.com/spf13/cobra"
 )
 
 // sessionStatsCmd represents the sessionStats command
 var getTorrentsCmd = &cobra.Command{
-	Use:   "torrents",
-	Short: "Get torrent information from transmission server",
+	Use:         "torrents [id|hash|hash-prefix|hash:prefix]...",
+	Annotations: map[string]string{fleetAnnotation: ""},
+	Short:       "Get torrent information from transmission server",
+	Long:        "Get torrent information from transmission server.\n\n" + torrentIDsHelp,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ids := make([]int, len(args))
-		for i, arg := range args {
-			id, err := strconv.ParseInt(arg, 10, 64)
-			if err != nil {
-				return fmt.Errorf("failed parsing int argument")
-			}
-			ids[i] = int(id)
+		query, err := torrentQuery()
+		if err != nil {
+			return err
+		}
+		ids, err := parseTorrentIDs(cmd.Context(), args)
+		if err != nil {
+			return err
+		}
+		var torrents interface{}
+		if fleet != nil {
+			var serverTorrents []transmission.ServerTorrent
+			serverTorrents, err = getFleetTorrents(cmd.Context(), ids)
+			torrents = query.ApplyServerTorrents(serverTorrents)
+		} else {
+			var list []transmission.Torrent
+			list, err = tr.GetTorrents(cmd.Context(), ids...)
+			torrents = query.Apply(list)
 		}
-		torrents, err := tr.GetTorrents(cmd.Context(), ids...)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Failed to get torrents:", err)
-			os.Exit(1)
+			exit(1)
 		}
 		if err := json.NewEncoder(os.Stdout).Encode(torrents); err != nil {
 			fmt.Fprintln(os.Stderr, "Failed to marshal output:", err)
-			os.Exit(1)
+			exit(1)
 		}
 		return nil
 	},
 }
 
+// getFleetTorrents gets the torrents of every server. Failed servers are
+// reported, and only fail the command if every server failed.
+func getFleetTorrents(ctx context.Context, ids []transmission.TorrentID) ([]transmission.ServerTorrent, error) {
+	torrents, err := fleet.GetTorrents(ctx, ids...)
+	var fleetErr *transmission.FleetError
+	if errors.As(err, &fleetErr) && !fleetErr.All() {
+		for _, serverErr := range fleetErr.Errors {
+			fmt.Fprintln(os.Stderr, "Failed to get torrents:", serverErr)
+		}
+		return torrents, nil
+	}
+	return torrents, err
+}
+
+var (
+	torrentsFilter string
+	torrentsSort   string
+	torrentsLimit  int
+)
+
+func torrentQuery() (*transmission.Query, error) {
+	query, err := transmission.ParseQuery(torrentsFilter)
+	if err != nil {
+		return nil, err
+	}
+	if query.Sort, err = transmission.ParseSortKeys(torrentsSort); err != nil {
+		return nil, err
+	}
+	query.Limit = torrentsLimit
+	return query, nil
+}
+
 func init() {
+	getTorrentsCmd.Flags().StringVar(&torrentsFilter, "filter", "", `Only show torrents matching a query, like 'status=seeding and ratio>2 and name~"S01"'`)
+	getTorrentsCmd.Flags().StringVar(&torrentsSort, "sort", "", "Sort by comma separated fields, prefixed with - for descending order, like -ratio,name")
+	getTorrentsCmd.Flags().IntVar(&torrentsLimit, "limit", 0, "Show at most this many torrents")
 	getCmd.AddCommand(getTorrentsCmd)
 }
@@ -0,0 +1,99 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/spf13/cobra"
+)
+
+// groupCmd represents the group command
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage bandwidth groups of transmission server",
+}
+
+// groupListCmd represents the group list command
+var groupListCmd = &cobra.Command{
+	Use:   "list [name]...",
+	Short: "Get bandwidth groups",
+	Run: func(cmd *cobra.Command, args []string) {
+		groups, err := tr.GetBandwidthGroups(cmd.Context(), args...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to get bandwidth groups:", err)
+			exit(1)
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(groups); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to marshal output:", err)
+			exit(1)
+		}
+	},
+}
+
+var groupDownLimit int
+var groupUpLimit int
+var groupHonorsSessionLimits bool
+
+// groupSetCmd represents the group set command
+var groupSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Create or update a bandwidth group",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("Missing group name")
+		}
+		group := transmission.BandwidthGroup{
+			Name:                  args[0],
+			HonorsSessionLimits:   groupHonorsSessionLimits,
+			SpeedLimitDownEnabled: groupDownLimit >= 0,
+			SpeedLimitUpEnabled:   groupUpLimit >= 0,
+		}
+		if group.SpeedLimitDownEnabled {
+			group.SpeedLimitDown = groupDownLimit
+		}
+		if group.SpeedLimitUpEnabled {
+			group.SpeedLimitUp = groupUpLimit
+		}
+		if err := tr.SetBandwidthGroup(cmd.Context(), group); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to set bandwidth group:", err)
+			exit(1)
+		}
+		return nil
+	},
+}
+
+// groupAssignCmd represents the group assign command
+var groupAssignCmd = &cobra.Command{
+	Use:   "assign <name> <id|hash|hash-prefix|hash:prefix>...",
+	Short: "Move torrents into a bandwidth group",
+	Long:  "Move torrents into a bandwidth group.\n\n" + torrentIDsHelp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return fmt.Errorf("Missing group name or torrents")
+		}
+		ids, err := parseTorrentIDs(cmd.Context(), args[1:])
+		if err != nil {
+			return err
+		}
+		if err := tr.AssignBandwidthGroup(cmd.Context(), args[0], ids...); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to assign bandwidth group:", err)
+			exit(1)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(groupCmd)
+	groupCmd.AddCommand(groupListCmd)
+	groupCmd.AddCommand(groupSetCmd)
+	groupCmd.AddCommand(groupAssignCmd)
+	groupSetCmd.Flags().IntVar(&groupDownLimit, "down", -1, "Download limit in kB/s, unlimited if negative")
+	groupSetCmd.Flags().IntVar(&groupUpLimit, "up", -1, "Upload limit in kB/s, unlimited if negative")
+	groupSetCmd.Flags().BoolVar(&groupHonorsSessionLimits, "honors-session-limits", true, "Whether the group is also limited by the session limits")
+}
@@ -4,36 +4,105 @@ Copyright © 2022 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/internal/fixture"
 	"github.com/spf13/cobra"
 )
 
 // rootCmd represents the base command when called without any subcommands
-var rootCmd = &cobra.Command{}
+var rootCmd = &cobra.Command{
+	// Connect once the flags are parsed so --base-url is honoured
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var opts []transmission.ClientOption
+		if tableFormat {
+			opts = append(opts, transmission.TableFormatOption())
+		}
+		if recordPath != "" {
+			recorder = fixture.NewRecorder(nil)
+			opts = append(opts, transmission.WrapTransportOption(recorder.Wrap))
+		}
+		var err error
+		if allServers {
+			if !supportsFleet(cmd) {
+				return fmt.Errorf("%s acts on a single server and can't be used with --all-servers, pick one with --use-server", strings.TrimSpace(cmd.CommandPath()))
+			}
+			if useServer != "" {
+				return fmt.Errorf("--use-server can't be used with --all-servers")
+			}
+			fleet, tr, err = connectFleet(cmd.Context(), opts)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to connect to servers:", err)
+				exit(1)
+			}
+			return nil
+		}
+		url := address
+		target = address
+		if len(servers) > 0 {
+			var name string
+			if name, url, err = pickServer(); err != nil {
+				return err
+			}
+			target = fmt.Sprintf("%s (%s)", name, url)
+		} else if useServer != "" {
+			return fmt.Errorf("--use-server needs --server")
+		}
+		tr, err = transmission.New(cmd.Context(), url, opts...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to connect to server:", err)
+			exit(1)
+		}
+		return nil
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		return saveRecording()
+	},
+}
+
+// saveRecording writes the calls recorded with --record, if any.
+func saveRecording() error {
+	if recorder == nil {
+		return nil
+	}
+	return recorder.Save(recordPath)
+}
+
+// exit saves the recording before exiting, which PersistentPostRunE is
+// skipped for, so failed calls end up in the fixture too.
+func exit(code int) {
+	if err := saveRecording(); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to save recording:", err)
+	}
+	os.Exit(code)
+}
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	// Start transmission interface
-	var err error
-	tr, err = transmission.New(context.Background(), address)
+	err := rootCmd.Execute()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to connect to server:", err)
-		os.Exit(1)
-	}
-	err = rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+		exit(1)
 	}
 }
 
 var address string
+var tableFormat bool
+var recordPath string
+var recorder *fixture.Recorder
 var tr *transmission.Client
 
+// target describes the server tr is connected to, for messages
+var target string
+
 func init() {
-	rootCmd.PersistentFlags().StringVar(&address, "base-url", "https://transmission.bobcob7.com", "URL to transmission server")
+	rootCmd.PersistentFlags().StringVar(&address, "base-url", "https://transmission.bobcob7.com", "URL to transmission server, or unix:///path/to/socket")
+	rootCmd.PersistentFlags().BoolVar(&tableFormat, "table-format", false, "Request torrent lists in the smaller table format")
+	rootCmd.PersistentFlags().StringArrayVar(&servers, "server", nil, "Server as name=url, or name:label,label=url to add torrents with those labels to it. Repeat for several servers, commands use the first one unless --use-server or --all-servers is set")
+	rootCmd.PersistentFlags().StringVar(&useServer, "use-server", "", "Name or label of the --server to use, the first one with that label if no server has that name")
+	rootCmd.PersistentFlags().BoolVar(&allServers, "all-servers", false, "Run get torrents and add torrent on every --server, other commands act on one server and refuse it")
+	rootCmd.PersistentFlags().StringVar(&recordPath, "record", "", "Record the RPC calls into a fixture file for transmissiontest")
 }
@@ -0,0 +1,54 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var shutdownYes bool
+var shutdownWait time.Duration
+
+// shutdownCmd represents the shutdown command
+var shutdownCmd = &cobra.Command{
+	Use:   "shutdown",
+	Short: "Shut down transmission server gracefully",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !shutdownYes {
+			fmt.Printf("Shut down transmission server %s? [y/N] ", target)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				fmt.Println("Aborted")
+				return
+			}
+		}
+		if err := tr.CloseSession(cmd.Context()); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to close session:", err)
+			exit(1)
+		}
+		if shutdownWait <= 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), shutdownWait)
+		defer cancel()
+		if err := tr.WaitForShutdown(ctx, time.Second); err != nil {
+			fmt.Fprintln(os.Stderr, "Server did not shut down:", err)
+			exit(1)
+		}
+		fmt.Println("Server shut down")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shutdownCmd)
+	shutdownCmd.Flags().BoolVarP(&shutdownYes, "yes", "y", false, "Don't ask for confirmation")
+	shutdownCmd.Flags().DurationVar(&shutdownWait, "wait", 0, "Wait up to this long for the server to stop answering")
+}
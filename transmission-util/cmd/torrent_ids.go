@@ -0,0 +1,134 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+// hashPrefix marks an argument as an info hash or hash prefix, for prefixes
+// that would otherwise read as numeric IDs.
+const hashPrefix = "hash:"
+
+// torrentIDsHelp describes the arguments parseTorrentIDs takes, for the help
+// of the commands using it.
+const torrentIDsHelp = `Torrents are given by numeric ID, info hash or a unique prefix of the info
+hash. A prefix of only digits reads as a numeric ID, write it as
+hash:<prefix> instead, like hash:1234. With --all-servers, which only get
+torrents takes, numeric IDs are refused, as they are per server.`
+
+// parseTorrentIDs parses numeric IDs, info hashes and "recently-active".
+// Any other hex string, or anything after hash:, is treated as an info hash
+// prefix and resolved against the torrents on the server, or with
+// --all-servers against the torrents of every server. Numeric IDs are per
+// server, so they are refused with --all-servers.
+func parseTorrentIDs(ctx context.Context, args []string) ([]transmission.TorrentID, error) {
+	ids := make([]transmission.TorrentID, len(args))
+	type hashPrefixArg struct {
+		index  int
+		prefix string
+	}
+	var prefixes []hashPrefixArg
+	for i, arg := range args {
+		if hash := strings.TrimPrefix(arg, hashPrefix); hash != arg {
+			if transmission.IsHashString(hash) {
+				ids[i] = transmission.Hash(hash)
+				continue
+			}
+			if !isHexPrefix(hash) {
+				return nil, fmt.Errorf("invalid hash prefix %q: must be hex", hash)
+			}
+			prefixes = append(prefixes, hashPrefixArg{i, strings.ToLower(hash)})
+			continue
+		}
+		id, err := transmission.ParseTorrentID(arg)
+		if err == nil {
+			if fleet != nil && !id.IsHash() && id != transmission.RecentlyActive {
+				return nil, fmt.Errorf("torrent ID %s is per server, select torrents by hash with --all-servers", id)
+			}
+			ids[i] = id
+			continue
+		}
+		if !isHexPrefix(arg) {
+			return nil, err
+		}
+		prefixes = append(prefixes, hashPrefixArg{i, strings.ToLower(arg)})
+	}
+	if len(prefixes) == 0 {
+		return ids, nil
+	}
+	hashes, err := torrentHashes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed resolving hash prefixes: %w", err)
+	}
+	for _, arg := range prefixes {
+		prefix := arg.prefix
+		var matches []string
+		for hash := range hashes {
+			if strings.HasPrefix(hash, prefix) {
+				matches = append(matches, hash)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("no torrent with hash prefix %q", prefix)
+		case 1:
+			ids[arg.index] = transmission.Hash(matches[0])
+		default:
+			return nil, fmt.Errorf("hash prefix %q is ambiguous, it matches %d torrents", prefix, len(matches))
+		}
+	}
+	return ids, nil
+}
+
+// torrentHashes returns the info hashes of the torrents on the server, or
+// with --all-servers on every server. A torrent on several servers is one
+// hash. Failed servers are reported, and only fail if every server failed.
+func torrentHashes(ctx context.Context) (map[string]bool, error) {
+	hashes := make(map[string]bool)
+	collect := func(torrent transmission.Torrent) error {
+		hashes[torrent.HashString] = true
+		return nil
+	}
+	fields := transmission.TorrentFieldsOption("hashString")
+	if fleet == nil {
+		if err := tr.IterateTorrents(ctx, collect, fields); err != nil {
+			return nil, err
+		}
+		return hashes, nil
+	}
+	var lastErr error
+	failed := 0
+	members := fleet.Members()
+	for _, member := range members {
+		if err := member.Client.IterateTorrents(ctx, collect, fields); err != nil {
+			lastErr = &transmission.ServerError{Server: member.Name, Err: err}
+			fmt.Fprintln(os.Stderr, "Failed to get torrents:", lastErr)
+			failed++
+		}
+	}
+	if failed == len(members) {
+		return nil, lastErr
+	}
+	return hashes, nil
+}
+
+// isHexPrefix reports whether s could be the start of a hex encoded hash.
+func isHexPrefix(s string) bool {
+	if s == "" {
+		return false
+	}
+	// Odd length prefixes are padded to be decodable
+	if len(s)%2 == 1 {
+		s += "0"
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/spf13/cobra"
+)
+
+var waitUntil string
+var waitTimeout time.Duration
+
+// parseCondition parses the --until flag of the wait command.
+func parseCondition(until string) (func(*transmission.Torrent) bool, error) {
+	name, value := until, ""
+	if i := strings.Index(until, "="); i >= 0 {
+		name, value = until[:i], until[i+1:]
+	}
+	switch name {
+	case "completed":
+		return transmission.Completed, nil
+	case "verified":
+		return transmission.Verified(), nil
+	case "seeding":
+		return transmission.Seeding, nil
+	case "stopped":
+		return transmission.Stopped, nil
+	case "metadata":
+		return transmission.MetadataReceived, nil
+	case "ratio":
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ratio %q", value)
+		}
+		return transmission.RatioReached(ratio), nil
+	case "moved-to":
+		if value == "" {
+			return nil, fmt.Errorf("missing directory for moved-to")
+		}
+		return transmission.MovedTo(value), nil
+	}
+	return nil, fmt.Errorf("unknown condition %q", until)
+}
+
+// waitCmd represents the wait command
+var waitCmd = &cobra.Command{
+	Use:   "wait <id|hash|hash-prefix|hash:prefix>",
+	Short: "Wait until a torrent reaches a state",
+	Long: `Wait until a torrent reaches a state, given by --until as one of
+completed, verified, seeding, stopped, metadata, ratio=<ratio> or moved-to=<dir>.
+Exits with status 1 if the timeout expires first. verified waits until a
+verify is seen and ends, so start waiting right after starting the verify,
+with a timeout in case it ended already.
+
+` + torrentIDsHelp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("Missing torrent")
+		}
+		cond, err := parseCondition(waitUntil)
+		if err != nil {
+			return err
+		}
+		ids, err := parseTorrentIDs(cmd.Context(), args)
+		if err != nil {
+			return err
+		}
+		ctx := cmd.Context()
+		if waitTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, waitTimeout)
+			defer cancel()
+		}
+		if _, err := tr.WaitFor(ctx, ids[0], cond); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed waiting for torrent:", err)
+			exit(1)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+	waitCmd.Flags().StringVar(&waitUntil, "until", "completed", "State to wait for")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 0, "Give up after this long, never by default")
+}
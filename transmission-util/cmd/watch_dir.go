@@ -0,0 +1,89 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/spf13/cobra"
+)
+
+var watchDirInterval time.Duration
+var watchDirPaused bool
+var watchDirOnce bool
+
+// watchDirCmd represents the watch-dir command
+var watchDirCmd = &cobra.Command{
+	Use:   "watch-dir <dir>",
+	Short: "Add .torrent and .magnet files dropped into a directory",
+	Long: `Add .torrent files, and .magnet or .txt files with one magnet link per line,
+dropped into a directory. Files in subdirectories download into the same
+subdirectory of the download directory. Processed files are renamed with
+.added or .failed appended. When only some links of a file fail, the added
+and failed links are split into a .added and a .failed file. Only torrents
+the server refuses are marked failed, when it can't be reached the file is
+left in place with the links still to add and retried at the next scan. .txt
+files without magnet links are left alone.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if info, err := os.Stat(args[0]); err != nil || !info.IsDir() {
+			fmt.Fprintln(os.Stderr, "Not a directory:", args[0])
+			exit(1)
+		}
+		var addOpts []transmission.AddMagnetLinkOption
+		if watchDirPaused {
+			addOpts = append(addOpts, transmission.PausedOption())
+		}
+		watcher := transmission.NewDirWatcher(tr, args[0],
+			transmission.DirPollIntervalOption(watchDirInterval),
+			transmission.DirAddOption(addOpts...),
+			transmission.DirResultOption(func(result transmission.DirResult) {
+				for _, link := range result.Links {
+					if link.Err != nil {
+						fmt.Fprintf(os.Stderr, "Failed to add %s: %v\n", link.Link, link.Err)
+					}
+				}
+				if result.Err != nil {
+					fmt.Fprintln(os.Stderr, "Failed to add", result)
+					return
+				}
+				fmt.Println(result)
+			}),
+		)
+		if watchDirOnce {
+			// Files are only added once unchanged between two scans
+			for i := 0; i < 2; i++ {
+				if i > 0 {
+					time.Sleep(watchDirInterval)
+				}
+				if _, err := watcher.Scan(cmd.Context()); err != nil {
+					fmt.Fprintln(os.Stderr, "Failed to scan directory:", err)
+					exit(1)
+				}
+			}
+			return
+		}
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		go func() {
+			<-interrupt
+			cancel()
+		}()
+		watcher.Run(ctx)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchDirCmd)
+	watchDirCmd.Flags().DurationVar(&watchDirInterval, "interval", 5*time.Second, "Time between scans of the directory")
+	watchDirCmd.Flags().BoolVar(&watchDirPaused, "paused", false, "Add the torrents without starting them")
+	watchDirCmd.Flags().BoolVar(&watchDirOnce, "once", false, "Scan the directory once and exit")
+}
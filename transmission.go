@@ -5,38 +5,141 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 )
 
 type Client struct {
-	rootURL     string
-	sessionID   string
-	DownloadDir string
-	cli         *http.Client
-	sessionInfo map[string]interface{}
+	requestCount  int64
+	tagPrefix     int64
+	rootURL       string
+	mu            sync.Mutex
+	sessionID     string
+	DownloadDir   string
+	cli           *http.Client
+	sessionInfo   map[string]interface{}
+	protocol      Protocol
+	socketPath    string
+	transport     http.RoundTripper
+	wrapTransport func(http.RoundTripper) http.RoundTripper
+	trace         func(context.Context, RequestTrace)
+	tableFormat   bool
 }
 
-func New(ctx context.Context, rootURL string) (*Client, error) {
+type ClientOption func(*Client)
+
+// ProtocolOption forces the client to use the given RPC protocol instead of
+// detecting it from the server.
+func ProtocolOption(protocol Protocol) ClientOption {
+	return func(c *Client) {
+		c.protocol = protocol
+	}
+}
+
+// UnixSocketOption makes the client dial the RPC server on a Unix domain
+// socket, as served by Transmission 4 with an rpc-bind-address of unix:/path.
+func UnixSocketOption(socketPath string) ClientOption {
+	return func(c *Client) {
+		c.socketPath = socketPath
+	}
+}
+
+// TransportOption sends requests through transport instead of the default
+// keep-alive transport, or the Unix socket transport.
+func TransportOption(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WrapTransportOption wraps the transport the client would use otherwise:
+// the keep-alive transport, the Unix socket transport or the one from
+// TransportOption. Use it to record or trace calls without changing how the
+// client connects.
+func WrapTransportOption(wrap func(http.RoundTripper) http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.wrapTransport = wrap
+	}
+}
+
+// New connects to the server at rootURL. A rootURL of the form
+// unix:///path/to/socket connects over a Unix domain socket.
+func New(ctx context.Context, rootURL string, opts ...ClientOption) (*Client, error) {
 	tr := &Client{
-		rootURL: rootURL,
+		rootURL:   rootURL,
+		tagPrefix: newTagPrefix(),
 		cli: &http.Client{
-			Timeout: time.Second * 10,
+			Timeout:   time.Second * 10,
+			Transport: keepAliveTransport(),
 		},
 	}
+	if strings.HasPrefix(rootURL, "unix:") {
+		tr.socketPath = strings.TrimPrefix(strings.TrimPrefix(rootURL, "unix:"), "//")
+	}
+	for _, opt := range opts {
+		opt(tr)
+	}
+	if tr.socketPath != "" {
+		tr.rootURL = "http://localhost"
+		tr.cli.Transport = unixSocketTransport(tr.socketPath)
+	}
+	if tr.transport != nil {
+		tr.cli.Transport = tr.transport
+	}
+	if tr.wrapTransport != nil {
+		tr.cli.Transport = tr.wrapTransport(tr.cli.Transport)
+	}
 	err := tr.getSessionID(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed getting session: %w", err)
 	}
+	if tr.protocol == ProtocolAuto {
+		if tr.protocol, err = tr.detectProtocol(ctx); err != nil {
+			return nil, fmt.Errorf("failed detecting protocol: %w", err)
+		}
+	}
 	if tr.sessionInfo, err = tr.GetSession(ctx); err != nil {
 		return nil, fmt.Errorf("failed getting session info: %w", err)
 	}
 	if downloadDir, ok := tr.sessionInfo["download-dir"]; ok {
 		tr.DownloadDir = downloadDir.(string)
 	}
+	if rpcVersion, _ := tr.sessionInfo["rpc-version"].(float64); rpcVersion < minTableFormatRPCVersion {
+		tr.tableFormat = false
+	}
 	return tr, nil
 }
 
+// maxIdleConns is large enough to keep a connection alive for every call
+// of a batch running at its default concurrency.
+const maxIdleConns = 16
+
+func keepAliveTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConns
+	return transport
+}
+
+func unixSocketTransport(socketPath string) *http.Transport {
+	return &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+		MaxIdleConnsPerHost: maxIdleConns,
+	}
+}
+
+// Protocol returns the RPC protocol the client uses to talk to the server.
+func (t *Client) Protocol() Protocol {
+	return t.protocol
+}
+
 func (t *Client) getSessionID(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.rootURL+"/transmission/rpc", nil)
 	if err != nil {
@@ -46,58 +149,176 @@ func (t *Client) getSessionID(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	resp.Body.Close()
 	sessionID := resp.Header.Get("X-Transmission-Session-Id")
 	if sessionID == "" {
 		return fmt.Errorf("missing header :%#v", resp.Header)
 	}
-	t.sessionID = sessionID
+	t.setSessionID(sessionID)
 	return nil
 }
 
+func (t *Client) getCurrentSessionID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID
+}
+
+func (t *Client) setSessionID(sessionID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sessionID = sessionID
+}
+
 type genericRequest struct {
 	Method    string      `json:"method"`
 	Arguments interface{} `json:"arguments"`
-	Tag       string      `json:"tag"`
+	Tag       int64       `json:"tag"`
+}
+
+type genericResponse struct {
+	Result string `json:"result"`
+	Tag    *int64 `json:"tag"`
 }
 
 func (t *Client) callRPC(ctx context.Context, requestMethod string, requestArguments, response interface{}) error {
-	var resp *http.Response
-	if t.sessionID == "" {
-		if err := t.getSessionID(ctx); err != nil {
-			return fmt.Errorf("error getting session ID: %w", err)
+	return t.traceCall(ctx, requestMethod, func(tag int64) error {
+		if t.protocol == ProtocolJSONRPC {
+			return t.callJSONRPC(ctx, requestMethod, tag, requestArguments, response)
 		}
+		return t.callLegacy(ctx, requestMethod, tag, requestArguments, response)
+	})
+}
+
+// traceCall runs an RPC call with a fresh tag, wrapping its error in a
+// RequestError and reporting it to the trace hook.
+func (t *Client) traceCall(ctx context.Context, requestMethod string, call func(tag int64) error) error {
+	tag := t.nextTag()
+	start := time.Now()
+	err := call(tag)
+	if err != nil {
+		err = &RequestError{Method: requestMethod, Tag: tag, Err: err}
 	}
-	buffer := new(bytes.Buffer)
-	request := genericRequest{
+	if t.trace != nil {
+		t.trace(ctx, RequestTrace{
+			Method:   requestMethod,
+			Tag:      tag,
+			Start:    start,
+			Duration: time.Since(start),
+			Err:      err,
+		})
+	}
+	return err
+}
+
+// encodeRequest encodes a call in the format of the client's protocol.
+func (t *Client) encodeRequest(requestMethod string, tag int64, requestArguments interface{}) ([]byte, error) {
+	var request interface{} = genericRequest{
 		Method:    requestMethod,
 		Arguments: requestArguments,
+		Tag:       tag,
+	}
+	if t.protocol == ProtocolJSONRPC {
+		rpcRequest := jsonRPCRequest{
+			JSONRPC: "2.0",
+			Method:  snakeCase(requestMethod),
+			ID:      tag,
+		}
+		if requestArguments != nil {
+			params, err := toGeneric(requestArguments)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode request: %w", err)
+			}
+			rpcRequest.Params = snakeKeys(params)
+		}
+		request = rpcRequest
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
 	}
-	err := json.NewEncoder(buffer).Encode(request)
+	return body, nil
+}
+
+func (t *Client) callLegacy(ctx context.Context, requestMethod string, tag int64, requestArguments, response interface{}) error {
+	body, err := t.encodeRequest(requestMethod, tag, requestArguments)
 	if err != nil {
-		return fmt.Errorf("failed to encode request: %w", err)
+		return err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.rootURL+"/transmission/rpc", buffer)
+	resp, err := t.post(ctx, body)
 	if err != nil {
-		return fmt.Errorf("failed to create new http request: %w", err)
+		return err
+	}
+	defer resp.Body.Close()
+	var envelope legacyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := checkTag(envelope.Tag, tag); err != nil {
+		return err
+	}
+	if envelope.Result != "success" {
+		return &RPCError{Message: envelope.Result}
+	}
+	return decodeArguments(envelope.Arguments, response)
+}
+
+// legacyResponse is the envelope of a legacy response. The arguments are kept
+// raw until the tag and result have been checked.
+type legacyResponse struct {
+	Result    string          `json:"result"`
+	Tag       *int64          `json:"tag"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// decodeArguments decodes the arguments of a response into the field of
+// response tagged json:"arguments", if it has one.
+func decodeArguments(arguments json.RawMessage, response interface{}) error {
+	v := reflect.ValueOf(response).Elem()
+	if len(arguments) == 0 || v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if jsonName(v.Type().Field(i)) == "arguments" {
+			return json.Unmarshal(arguments, v.Field(i).Addr().Interface())
+		}
+	}
+	return nil
+}
+
+// post sends an encoded request to the RPC endpoint, refreshing the session ID
+// when the server asks for it. The caller must close the response body.
+func (t *Client) post(ctx context.Context, body []byte) (*http.Response, error) {
+	var resp *http.Response
+	if t.getCurrentSessionID() == "" {
+		if err := t.getSessionID(ctx); err != nil {
+			return nil, fmt.Errorf("error getting session ID: %w", err)
+		}
 	}
 	for i := 0; i < 2; i++ {
-		req.Header.Add("X-Transmission-Session-Id", t.sessionID)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.rootURL+"/transmission/rpc", bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create new http request: %w", err)
+		}
+		req.Header.Set("X-Transmission-Session-Id", t.getCurrentSessionID())
 		resp, err = t.cli.Do(req)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if resp.StatusCode != 409 {
+		if resp.StatusCode != http.StatusConflict {
 			break
 		}
-		if err := t.getSessionID(ctx); err != nil {
-			return err
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if sessionID := resp.Header.Get("X-Transmission-Session-Id"); sessionID != "" {
+			t.setSessionID(sessionID)
+		} else if err := t.getSessionID(ctx); err != nil {
+			return nil, err
 		}
 	}
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
-		return err
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
-	return nil
+	return resp, nil
 }
@@ -0,0 +1,22 @@
+package transmission_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/transmissiontest"
+)
+
+// newTestClient starts a fake daemon and connects a client to it. The server
+// is closed when the test ends.
+func newTestClient(tb testing.TB, opts ...transmissiontest.Option) (*transmission.Client, *transmissiontest.Server) {
+	tb.Helper()
+	server := transmissiontest.NewServer(opts...)
+	tb.Cleanup(server.Close)
+	client, err := transmission.New(context.Background(), server.URL)
+	if err != nil {
+		tb.Fatalf("New: %v", err)
+	}
+	return client, server
+}
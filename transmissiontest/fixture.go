@@ -0,0 +1,57 @@
+package transmissiontest
+
+import (
+	"net/http"
+
+	"github.com/bobcob7/transmission-rpc/internal/fixture"
+)
+
+// Redacted replaces the values of redacted fields in fixtures.
+const Redacted = fixture.Redacted
+
+// Fixture is a recorded sequence of RPC calls, stored as JSON in golden files.
+type Fixture = fixture.Fixture
+
+// Interaction is one RPC call and the daemon's response to it. Headers aren't
+// recorded, so session IDs and Authorization headers never reach the file.
+type Interaction = fixture.Interaction
+
+// Recorder is an http.RoundTripper that records the RPC calls a client makes
+// to a real daemon. Pass it to transmission.TransportOption, or its Wrap
+// method to transmission.WrapTransportOption to record through the transport
+// the client would use anyway, and Save the fixture once done.
+type Recorder = fixture.Recorder
+
+type RecorderOption = fixture.RecorderOption
+
+// Replayer is an http.RoundTripper that answers a client's calls from a
+// fixture. Each call gets the first unused response recorded for its method,
+// with the tag or id of the call. It answers the session ID handshake itself.
+type Replayer = fixture.Replayer
+
+// LoadFixture reads a golden file written by a Recorder.
+func LoadFixture(path string) (*Fixture, error) {
+	return fixture.LoadFixture(path)
+}
+
+// RedactFieldsOption redacts more fields from requests and responses, like
+// download-dir to keep paths out of fixtures. The session-id field is always
+// redacted.
+func RedactFieldsOption(fields ...string) RecorderOption {
+	return fixture.RedactFieldsOption(fields...)
+}
+
+// NewRecorder records the calls sent through next, or http.DefaultTransport if
+// next is nil.
+func NewRecorder(next http.RoundTripper, opts ...RecorderOption) *Recorder {
+	return fixture.NewRecorder(next, opts...)
+}
+
+func NewReplayer(f *Fixture) *Replayer {
+	return fixture.NewReplayer(f)
+}
+
+// LoadReplayer replays a golden file written by a Recorder.
+func LoadReplayer(path string) (*Replayer, error) {
+	return fixture.LoadReplayer(path)
+}
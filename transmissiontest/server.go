@@ -0,0 +1,305 @@
+// Package transmissiontest provides a fake Transmission daemon for testing
+// code that uses transmission.Client.
+package transmissiontest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+// Request is an RPC call received by the server.
+type Request struct {
+	Method    string          `json:"method"`
+	Arguments json.RawMessage `json:"arguments"`
+	Tag       *int64          `json:"tag,omitempty"`
+}
+
+type response struct {
+	Arguments interface{} `json:"arguments"`
+	Result    string      `json:"result"`
+	Tag       *int64      `json:"tag,omitempty"`
+}
+
+type failure struct {
+	result     string
+	statusCode int
+	// times is the number of calls left to fail, or negative to fail forever
+	times int
+}
+
+// Server is a fake Transmission daemon backed by an in-memory model. It speaks
+// the legacy RPC protocol, including the session ID handshake.
+type Server struct {
+	// URL is the root URL to pass to transmission.New
+	URL string
+
+	server       *httptest.Server
+	stop         chan struct{}
+	mu           sync.Mutex
+	now          func() time.Time
+	sessionID    string
+	session      map[string]interface{}
+	cumulative   transmission.SessionStatistics
+	torrents     []*torrentState
+	nextID       int
+	removed      []removal
+	metadata     map[string]transmission.Torrent
+	downloadRate int
+	uploadRate   int
+	freeSpace    int64
+	latency      map[string]time.Duration
+	failures     map[string]*failure
+	requests     []Request
+	closed       bool
+}
+
+type Option func(*Server)
+
+// LatencyOption delays every response by d.
+func LatencyOption(d time.Duration) Option {
+	return func(s *Server) {
+		s.latency[""] = d
+	}
+}
+
+// RateOption sets the speed, in bytes per second, at which running torrents
+// download and seed when time is advanced. Both default to 1 MiB/s.
+func RateOption(download, upload int) Option {
+	return func(s *Server) {
+		s.downloadRate = download
+		s.uploadRate = upload
+	}
+}
+
+// ClockOption sets the clock used for dates and recently active torrents.
+func ClockOption(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// TickOption advances the simulation by interval every interval, until the
+// server is closed. By default time only moves when Advance is called.
+func TickOption(interval time.Duration) Option {
+	return func(s *Server) {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-s.stop:
+					return
+				case <-ticker.C:
+					s.Advance(interval)
+				}
+			}
+		}()
+	}
+}
+
+// NewServer starts a fake daemon. Close it when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		stop:         make(chan struct{}),
+		now:          time.Now,
+		sessionID:    newSessionID(),
+		session:      defaultSession(),
+		nextID:       1,
+		metadata:     make(map[string]transmission.Torrent),
+		downloadRate: 1 << 20,
+		uploadRate:   1 << 20,
+		freeSpace:    1 << 40,
+		latency:      make(map[string]time.Duration),
+		failures:     make(map[string]*failure),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+	s.server.Close()
+}
+
+func newSessionID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// RotateSessionID changes the session ID, so the next request of a client is
+// answered with 409 Conflict.
+func (s *Server) RotateSessionID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessionID = newSessionID()
+}
+
+// SetLatency delays the responses to method by d. An empty method applies to
+// every method without a latency of its own.
+func (s *Server) SetLatency(method string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency[method] = d
+}
+
+// FailMethod makes the next times calls of method fail with the given result
+// string. A negative times fails every call until ClearFailures.
+func (s *Server) FailMethod(method, result string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = &failure{result: result, times: times}
+}
+
+// FailHTTP makes the next times calls of method fail with the given HTTP
+// status code. A negative times fails every call until ClearFailures.
+func (s *Server) FailHTTP(method string, statusCode, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = &failure{statusCode: statusCode, times: times}
+}
+
+// ClearFailures removes every injected failure.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = make(map[string]*failure)
+}
+
+// Requests returns the RPC calls received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// takeFailure returns the failure to inject into a call of method, if any.
+func (s *Server) takeFailure(method string) *failure {
+	f, ok := s.failures[method]
+	if !ok || f.times == 0 {
+		return nil
+	}
+	if f.times > 0 {
+		f.times--
+	}
+	return f
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	sessionID := s.sessionID
+	s.mu.Unlock()
+	w.Header().Set("X-Transmission-Session-Id", sessionID)
+	if r.Method != http.MethodPost || r.Header.Get("X-Transmission-Session-Id") != sessionID {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	latency, ok := s.latency[req.Method]
+	if !ok {
+		latency = s.latency[""]
+	}
+	injected := s.takeFailure(req.Method)
+	s.mu.Unlock()
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	resp := response{
+		Arguments: struct{}{},
+		Result:    "success",
+		Tag:       req.Tag,
+	}
+	switch {
+	case injected != nil && injected.statusCode != 0:
+		w.WriteHeader(injected.statusCode)
+		return
+	case injected != nil:
+		resp.Result = injected.result
+	default:
+		s.mu.Lock()
+		arguments, err := s.call(req.Method, req.Arguments)
+		s.mu.Unlock()
+		if err != nil {
+			resp.Result = err.Error()
+		} else if arguments != nil {
+			resp.Arguments = arguments
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+type methodError string
+
+func (e methodError) Error() string {
+	return string(e)
+}
+
+// call runs an RPC method against the model. The caller must hold s.mu.
+func (s *Server) call(method string, arguments json.RawMessage) (interface{}, error) {
+	switch method {
+	case "session-get":
+		return s.sessionGet(arguments)
+	case "session-set":
+		return nil, s.sessionSet(arguments)
+	case "session-stats":
+		return s.sessionStats(), nil
+	case "session-close":
+		return nil, nil
+	case "blocklist-update":
+		return s.blocklistUpdate()
+	case "free-space":
+		return s.freeSpaceGet(arguments)
+	case "torrent-add":
+		return s.torrentAdd(arguments)
+	case "torrent-get":
+		return s.torrentGet(arguments)
+	case "torrent-set":
+		return nil, s.torrentSet(arguments)
+	case "torrent-remove":
+		return nil, s.torrentRemove(arguments)
+	case "torrent-set-location":
+		return nil, s.torrentSetLocation(arguments)
+	case "torrent-start", "torrent-start-now":
+		return nil, s.torrentAction(arguments, s.start)
+	case "torrent-stop":
+		return nil, s.torrentAction(arguments, s.stopTorrent)
+	case "torrent-verify":
+		return nil, s.torrentAction(arguments, s.verify)
+	case "torrent-reannounce":
+		return nil, s.torrentAction(arguments, func(*torrentState) {})
+	case "queue-move-top", "queue-move-up", "queue-move-down", "queue-move-bottom":
+		return nil, s.queueMove(method, arguments)
+	}
+	// Also what a legacy daemon answers to a JSON-RPC request
+	return nil, methodError("method name not recognized")
+}
@@ -0,0 +1,260 @@
+package transmissiontest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/transmissiontest"
+)
+
+const testMagnet = "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567&dn=test"
+
+// statusCounter counts the HTTP status codes of the responses it passes on.
+type statusCounter struct {
+	mu    sync.Mutex
+	codes map[int]int
+}
+
+func (c *statusCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil {
+		c.mu.Lock()
+		c.codes[resp.StatusCode]++
+		c.mu.Unlock()
+	}
+	return resp, err
+}
+
+func (c *statusCounter) count(code int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.codes[code]
+}
+
+func newClient(t *testing.T, opts ...transmissiontest.Option) (*transmission.Client, *transmissiontest.Server) {
+	t.Helper()
+	server := transmissiontest.NewServer(opts...)
+	t.Cleanup(server.Close)
+	client, err := transmission.New(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return client, server
+}
+
+func getTorrent(t *testing.T, client *transmission.Client, id int) transmission.Torrent {
+	t.Helper()
+	torrents, err := client.GetTorrents(context.Background(), transmission.ID(id))
+	if err != nil {
+		t.Fatalf("GetTorrents: %v", err)
+	}
+	if len(torrents) != 1 {
+		t.Fatalf("got %d torrents for ID %d", len(torrents), id)
+	}
+	return torrents[0]
+}
+
+func TestSessionIDHandshake(t *testing.T) {
+	server := transmissiontest.NewServer()
+	defer server.Close()
+	counter := &statusCounter{codes: make(map[int]int)}
+	client, err := transmission.New(context.Background(), server.URL, transmission.TransportOption(counter))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	conflicts := counter.count(http.StatusConflict)
+	if conflicts == 0 {
+		t.Fatal("connecting didn't go through a 409 Conflict")
+	}
+
+	server.RotateSessionID()
+	if _, err := client.GetSession(context.Background()); err != nil {
+		t.Fatalf("GetSession after rotation: %v", err)
+	}
+	if got := counter.count(http.StatusConflict); got != conflicts+1 {
+		t.Errorf("got %d conflicts after rotation, want %d", got, conflicts+1)
+	}
+	if _, err := client.GetSession(context.Background()); err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if got := counter.count(http.StatusConflict); got != conflicts+1 {
+		t.Errorf("the new session ID wasn't kept, got %d conflicts", got)
+	}
+}
+
+func TestTorrentLifecycle(t *testing.T) {
+	client, server := newClient(t)
+	ctx := context.Background()
+
+	id, err := client.AddMagnetLink(ctx, testMagnet, transmission.PausedOption())
+	if err != nil {
+		t.Fatalf("AddMagnetLink: %v", err)
+	}
+	torrent := getTorrent(t, client, id)
+	if torrent.Name != "test" || torrent.Status != transmission.StatusStopped {
+		t.Errorf("added torrent %q with status %d, want a stopped torrent named test", torrent.Name, torrent.Status)
+	}
+	duplicate, err := client.AddMagnetLink(ctx, testMagnet)
+	if err != nil || duplicate != id {
+		t.Errorf("adding again gave ID %d, error %v, want the duplicate's ID %d", duplicate, err, id)
+	}
+
+	if err := client.SetTorrents(ctx, transmission.IDs(id), transmission.DownloadLimitOption(100)); err != nil {
+		t.Fatalf("SetTorrents: %v", err)
+	}
+	if torrent := getTorrent(t, client, id); !torrent.DownloadLimited || torrent.DownloadLimit != 100 {
+		t.Errorf("download limit %d, limited %v after setting it", torrent.DownloadLimit, torrent.DownloadLimited)
+	}
+
+	if err := client.StartTorrents(ctx, transmission.ID(id)); err != nil {
+		t.Fatalf("StartTorrents: %v", err)
+	}
+	if status := getTorrent(t, client, id).Status; status != transmission.StatusDownload {
+		t.Errorf("status %d after start, want downloading", status)
+	}
+	if err := client.StopTorrents(ctx, transmission.ID(id)); err != nil {
+		t.Fatalf("StopTorrents: %v", err)
+	}
+	if status := getTorrent(t, client, id).Status; status != transmission.StatusStopped {
+		t.Errorf("status %d after stop, want stopped", status)
+	}
+
+	if err := client.RemoveTorrents(ctx, false, transmission.ID(id)); err != nil {
+		t.Fatalf("RemoveTorrents: %v", err)
+	}
+	if torrents := server.Torrents(); len(torrents) != 0 {
+		t.Errorf("%d torrents left after remove", len(torrents))
+	}
+}
+
+func TestQueueMoves(t *testing.T) {
+	client, server := newClient(t)
+	ctx := context.Background()
+	var ids []int
+	for i := 0; i < 4; i++ {
+		ids = append(ids, server.AddTorrent(transmission.Torrent{}))
+	}
+	order := func() []int {
+		var order []int
+		for _, torrent := range server.Torrents() {
+			order = append(order, torrent.ID)
+		}
+		return order
+	}
+	moves := []struct {
+		name string
+		move func(context.Context, ...transmission.TorrentID) error
+		id   int
+		want []int
+	}{
+		{"top", client.QueueMoveTop, ids[3], []int{ids[3], ids[0], ids[1], ids[2]}},
+		{"bottom", client.QueueMoveBottom, ids[3], []int{ids[0], ids[1], ids[2], ids[3]}},
+		{"up", client.QueueMoveUp, ids[2], []int{ids[0], ids[2], ids[1], ids[3]}},
+		{"down", client.QueueMoveDown, ids[0], []int{ids[2], ids[0], ids[1], ids[3]}},
+	}
+	for _, m := range moves {
+		if err := m.move(ctx, transmission.ID(m.id)); err != nil {
+			t.Fatalf("move %s: %v", m.name, err)
+		}
+		got := order()
+		for i := range m.want {
+			if got[i] != m.want[i] {
+				t.Errorf("queue after move %s is %v, want %v", m.name, got, m.want)
+				break
+			}
+		}
+		for position, id := range got {
+			if torrent := getTorrent(t, client, id); torrent.QueuePosition != position {
+				t.Errorf("torrent %d has queue position %d at %d", id, torrent.QueuePosition, position)
+			}
+		}
+	}
+}
+
+func TestFailMethod(t *testing.T) {
+	client, server := newClient(t)
+	ctx := context.Background()
+	server.FailMethod("torrent-get", "too many cooks", 1)
+	_, err := client.GetTorrents(ctx)
+	var rpcErr *transmission.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Message != "too many cooks" {
+		t.Errorf("got error %v, want the injected result", err)
+	}
+	if _, err := client.GetTorrents(ctx); err != nil {
+		t.Errorf("the failure outlasted its count: %v", err)
+	}
+
+	server.FailHTTP("session-get", http.StatusInternalServerError, -1)
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetSession(ctx); err == nil || !strings.Contains(err.Error(), "500") {
+			t.Errorf("got error %v, want a 500 status", err)
+		}
+	}
+	server.ClearFailures()
+	if _, err := client.GetSession(ctx); err != nil {
+		t.Errorf("GetSession after ClearFailures: %v", err)
+	}
+}
+
+func TestSetLatency(t *testing.T) {
+	client, server := newClient(t)
+	server.SetLatency("session-stats", time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetSessionStats(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want the deadline to pass", err)
+	}
+	if _, err := client.GetTorrents(context.Background()); err != nil {
+		t.Errorf("latency applied to another method: %v", err)
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	client, server := newClient(t, transmissiontest.RateOption(1<<20, 1<<20))
+	ctx := context.Background()
+	var torrent transmission.Torrent
+	torrent.Files = []transmission.TorrentFile{{Name: "a", Length: 3 << 20}}
+	id := server.AddTorrent(torrent)
+	if err := client.StartTorrents(ctx, transmission.ID(id)); err != nil {
+		t.Fatalf("StartTorrents: %v", err)
+	}
+
+	server.Advance(time.Second)
+	got := getTorrent(t, client, id)
+	if got.DownloadedEver != 1<<20 || got.LeftUntilDone != 2<<20 || got.Status != transmission.StatusDownload {
+		t.Errorf("after 1s: downloaded %d, left %d, status %d", got.DownloadedEver, got.LeftUntilDone, got.Status)
+	}
+	server.Advance(2 * time.Second)
+	got = getTorrent(t, client, id)
+	if got.LeftUntilDone != 0 || got.PercentDone != 1 || got.Status != transmission.StatusSeed {
+		t.Errorf("after 3s: left %d, done %v, status %d, want a seeding torrent", got.LeftUntilDone, got.PercentDone, got.Status)
+	}
+}
+
+func TestAdvanceMetadata(t *testing.T) {
+	client, server := newClient(t)
+	ctx := context.Background()
+	var metadata transmission.Torrent
+	metadata.HashString = "0123456789abcdef0123456789abcdef01234567"
+	metadata.Name = "with metadata"
+	metadata.Files = []transmission.TorrentFile{{Name: "with metadata/a", Length: 100}}
+	server.RegisterMetadata(metadata)
+	id, err := client.AddMagnetLink(ctx, testMagnet)
+	if err != nil {
+		t.Fatalf("AddMagnetLink: %v", err)
+	}
+	if got := getTorrent(t, client, id); got.MetadataPercentComplete != 0 || len(got.Files) != 0 {
+		t.Fatalf("magnet has metadata before any time passed")
+	}
+	server.Advance(time.Second)
+	got := getTorrent(t, client, id)
+	if got.MetadataPercentComplete != 1 || got.Name != "with metadata" || len(got.Files) != 1 {
+		t.Errorf("after 1s: metadata %v, name %q, %d files", got.MetadataPercentComplete, got.Name, len(got.Files))
+	}
+}
@@ -0,0 +1,186 @@
+package transmissiontest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+func defaultSession() map[string]interface{} {
+	return map[string]interface{}{
+		"alt-speed-enabled":            false,
+		"blocklist-enabled":            false,
+		"blocklist-size":               0,
+		"blocklist-url":                "http://www.example.com/blocklist",
+		"download-dir":                 "/downloads",
+		"download-queue-enabled":       true,
+		"download-queue-size":          5,
+		"incomplete-dir":               "/downloads/incomplete",
+		"incomplete-dir-enabled":       false,
+		"peer-port":                    51413,
+		"rpc-version":                  17,
+		"rpc-version-minimum":          14,
+		"rpc-version-semver":           "5.3.0",
+		"seedRatioLimit":               2,
+		"seedRatioLimited":             false,
+		"speed-limit-down":             100,
+		"speed-limit-down-enabled":     false,
+		"speed-limit-up":               100,
+		"speed-limit-up-enabled":       false,
+		"start-added-torrents":         true,
+		"version":                      "4.0.0 (transmissiontest)",
+		"seed-queue-enabled":           false,
+		"seed-queue-size":              10,
+		"queue-stalled-enabled":        true,
+		"queue-stalled-minutes":        30,
+		"rename-partial-files":         true,
+		"trash-original-torrent-files": false,
+	}
+}
+
+type sessionGetRequestArgs struct {
+	Fields []string `json:"fields"`
+}
+
+// SetSession changes session settings, as session-set would.
+func (s *Server) SetSession(settings map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, value := range settings {
+		s.session[key] = value
+	}
+}
+
+// Session returns the session settings.
+func (s *Server) Session() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := make(map[string]interface{}, len(s.session))
+	for key, value := range s.session {
+		session[key] = value
+	}
+	return session
+}
+
+func (s *Server) sessionGet(arguments json.RawMessage) (interface{}, error) {
+	var req sessionGetRequestArgs
+	if err := unmarshalArguments(arguments, &req); err != nil {
+		return nil, err
+	}
+	session := make(map[string]interface{}, len(s.session))
+	for key, value := range s.session {
+		session[key] = value
+	}
+	session["session-id"] = s.sessionID
+	if len(req.Fields) == 0 {
+		return session, nil
+	}
+	filtered := make(map[string]interface{}, len(req.Fields))
+	for _, field := range req.Fields {
+		if value, ok := session[field]; ok {
+			filtered[field] = value
+		}
+	}
+	return filtered, nil
+}
+
+func (s *Server) sessionSet(arguments json.RawMessage) error {
+	var settings map[string]interface{}
+	if err := unmarshalArguments(arguments, &settings); err != nil {
+		return err
+	}
+	for key, value := range settings {
+		switch key {
+		case "blocklist-size", "rpc-version", "rpc-version-minimum", "rpc-version-semver", "version", "session-id":
+			// read only
+		default:
+			s.session[key] = value
+		}
+	}
+	return nil
+}
+
+// blocklistUpdate downloads the blocklist from blocklist-url and counts its
+// rules, the lines that aren't empty or comments. Unlike the daemon it
+// doesn't parse the rules or check the blocklist is enabled.
+func (s *Server) blocklistUpdate() (interface{}, error) {
+	blocklistURL, _ := s.session["blocklist-url"].(string)
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(blocklistURL)
+	if err != nil {
+		return nil, methodError(fmt.Sprintf("blocklist download failed: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, methodError(fmt.Sprintf("blocklist download failed: %s", resp.Status))
+	}
+	size := 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			size++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, methodError(fmt.Sprintf("blocklist download failed: %v", err))
+	}
+	s.session["blocklist-size"] = size
+	return map[string]interface{}{"blocklist-size": size}, nil
+}
+
+func (s *Server) sessionStats() transmission.Session {
+	stats := transmission.Session{
+		TorrentCount:    len(s.torrents),
+		CumulativeStats: s.cumulative,
+		CurrentStats:    s.cumulative,
+	}
+	for _, t := range s.torrents {
+		if t.Status == transmission.StatusStopped {
+			stats.PausedTorrentCount++
+		} else {
+			stats.ActiveTorrentCount++
+		}
+		stats.DownloadSpeed += t.RateDownload
+		stats.UploadSpeed += t.RateUpload
+	}
+	return stats
+}
+
+// SetFreeSpace sets the free space free-space reports, 1 TiB by default.
+func (s *Server) SetFreeSpace(freeBytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.freeSpace = freeBytes
+}
+
+type freeSpaceRequestArgs struct {
+	Path string `json:"path"`
+}
+
+func (s *Server) freeSpaceGet(arguments json.RawMessage) (interface{}, error) {
+	var req freeSpaceRequestArgs
+	if err := unmarshalArguments(arguments, &req); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"path":       req.Path,
+		"size-bytes": s.freeSpace,
+		"total_size": 2 * s.freeSpace,
+	}, nil
+}
+
+func unmarshalArguments(arguments json.RawMessage, v interface{}) error {
+	if len(arguments) == 0 || string(arguments) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(arguments, v); err != nil {
+		return methodError("invalid arguments: " + err.Error())
+	}
+	return nil
+}
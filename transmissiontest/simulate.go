@@ -0,0 +1,119 @@
+package transmissiontest
+
+import (
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+// Advance moves the simulation forward by d. Magnet links receive their
+// metadata, downloading torrents progress at the download rate and move on to
+// seeding once complete, seeding torrents upload at the upload rate and stop
+// at their seed ratio limit, and verifies finish.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seconds := d.Seconds()
+	for _, t := range s.torrents {
+		switch t.Status {
+		case transmission.StatusCheckWait, transmission.StatusCheck:
+			t.RecheckProgress = 0
+			t.Status = t.resumeStatus
+			if t.Status == transmission.StatusDownload && t.LeftUntilDone == 0 {
+				t.Status = transmission.StatusSeed
+			}
+		case transmission.StatusDownload, transmission.StatusDownloadWait:
+			s.download(t, int(float64(s.downloadRate)*seconds))
+			t.SecondsDownloading += int(seconds)
+		case transmission.StatusSeed, transmission.StatusSeedWait:
+			s.seed(t, int(float64(s.uploadRate)*seconds))
+			t.SecondsSeeding += int(seconds)
+		default:
+			continue
+		}
+		t.ActivityDate = uint64(s.now().Unix())
+		s.touch(t)
+	}
+	s.cumulative.SecondsActive += int(seconds)
+}
+
+func (s *Server) download(t *torrentState, bytes int) {
+	if t.MetadataPercentComplete < 1 {
+		s.applyMetadata(&t.Torrent)
+		t.updateSizes()
+		return
+	}
+	t.RateDownload = s.downloadRate
+	t.RateUpload = 0
+	for i := range t.Files {
+		if bytes <= 0 {
+			break
+		}
+		if !t.FileStats[i].Wanted {
+			continue
+		}
+		n := t.Files[i].Length - t.Files[i].BytesCompleted
+		if n > bytes {
+			n = bytes
+		}
+		t.Files[i].BytesCompleted += n
+		t.FileStats[i].BytesCompleted = t.Files[i].BytesCompleted
+		t.DownloadedEver += uint64(n)
+		s.cumulative.DownloadedBytes += n
+		bytes -= n
+	}
+	t.updateSizes()
+	t.PercentComplete = t.PercentDone
+	if t.LeftUntilDone > 0 {
+		t.ETA = -1
+		if s.downloadRate > 0 {
+			t.ETA = int(t.LeftUntilDone) / s.downloadRate
+		}
+		return
+	}
+	t.Status = transmission.StatusSeed
+	t.DoneDate = uint64(s.now().Unix())
+	t.RateDownload = 0
+	t.ETA = -1
+}
+
+func (s *Server) seed(t *torrentState, bytes int) {
+	t.RateDownload = 0
+	t.RateUpload = s.uploadRate
+	t.UploadedEver += uint64(bytes)
+	s.cumulative.UploadedBytes += bytes
+	if t.SizeWhenDone > 0 {
+		t.UploadRatio = float64(t.UploadedEver) / float64(t.SizeWhenDone)
+		t.Ratio = float32(t.UploadRatio)
+	}
+	limit, limited := s.seedRatioLimit(t)
+	if !limited {
+		t.SeedRatioPercentDone = 1
+		return
+	}
+	t.SeedRatioPercentDone = 1
+	if limit > 0 && t.UploadRatio < limit {
+		t.SeedRatioPercentDone = float32(t.UploadRatio / limit)
+		return
+	}
+	t.Status = transmission.StatusStopped
+	t.IsFinished = true
+	t.RateUpload = 0
+}
+
+// seedRatioLimit returns the ratio a torrent stops seeding at, following its
+// seed ratio mode.
+func (s *Server) seedRatioLimit(t *torrentState) (float64, bool) {
+	switch t.SeedRatioMode {
+	case 1:
+		return t.SeedRatioLimit, true
+	case 2:
+		return 0, false
+	}
+	limited, _ := s.session["seedRatioLimited"].(bool)
+	limit, _ := s.session["seedRatioLimit"].(float64)
+	if value, ok := s.session["seedRatioLimit"].(int); ok {
+		limit = float64(value)
+	}
+	return limit, limited
+}
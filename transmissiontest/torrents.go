@@ -0,0 +1,631 @@
+package transmissiontest
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/metainfo"
+)
+
+// recentlyActive is how long a change keeps a torrent in the recently-active
+// selection, as in Transmission.
+const recentlyActive = 60 * time.Second
+
+type torrentState struct {
+	transmission.Torrent
+	changed time.Time
+	// resumeStatus is the status to return to after a verify
+	resumeStatus int
+}
+
+type removal struct {
+	id   int
+	time time.Time
+}
+
+// AddTorrent adds a torrent to the model and returns its ID. Fields derived
+// from the files, like TotalSize and FileStats, are filled in when empty.
+func (s *Server) AddTorrent(torrent transmission.Torrent) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addTorrent(torrent)
+}
+
+func (s *Server) addTorrent(torrent transmission.Torrent) int {
+	torrent.ID = s.nextID
+	s.nextID++
+	if torrent.HashString == "" {
+		sum := sha1.Sum([]byte(newSessionID()))
+		torrent.HashString = hex.EncodeToString(sum[:])
+	}
+	if torrent.AddedDate == 0 {
+		torrent.AddedDate = uint64(s.now().Unix())
+	}
+	if torrent.DownloadDir == "" {
+		torrent.DownloadDir, _ = s.session["download-dir"].(string)
+	}
+	if len(torrent.Files) > 0 && torrent.MetadataPercentComplete == 0 {
+		torrent.MetadataPercentComplete = 1
+	}
+	torrent.QueuePosition = len(s.torrents)
+	state := &torrentState{Torrent: torrent}
+	state.updateSizes()
+	s.torrents = append(s.torrents, state)
+	s.touch(state)
+	return torrent.ID
+}
+
+// RegisterMetadata makes magnet links for torrent.HashString receive the name
+// and files of torrent once their metadata has downloaded.
+func (s *Server) RegisterMetadata(torrent transmission.Torrent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metadata[strings.ToLower(torrent.HashString)] = torrent
+}
+
+// Torrent returns the current state of a torrent.
+func (s *Server) Torrent(id int) (transmission.Torrent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.torrents {
+		if t.ID == id {
+			return t.Torrent, true
+		}
+	}
+	return transmission.Torrent{}, false
+}
+
+// Torrents returns the current state of every torrent, in queue order.
+func (s *Server) Torrents() []transmission.Torrent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	torrents := make([]transmission.Torrent, len(s.torrents))
+	for i, t := range s.torrents {
+		torrents[i] = t.Torrent
+	}
+	return torrents
+}
+
+func (s *Server) touch(t *torrentState) {
+	t.changed = s.now()
+}
+
+// updateSizes recomputes the size fields from the files and wanted flags.
+func (t *torrentState) updateSizes() {
+	if len(t.Files) == 0 {
+		return
+	}
+	if len(t.FileStats) != len(t.Files) {
+		t.FileStats = make([]transmission.TorrentFileStats, len(t.Files))
+		for i := range t.FileStats {
+			t.FileStats[i] = transmission.TorrentFileStats{
+				BytesCompleted: t.Files[i].BytesCompleted,
+				Wanted:         true,
+			}
+		}
+	}
+	t.Priorities = make([]int, len(t.Files))
+	t.TotalSize = 0
+	t.FileCount = len(t.Files)
+	var sizeWhenDone, have uint64
+	for i, file := range t.Files {
+		t.TotalSize += file.Length
+		t.Priorities[i] = t.FileStats[i].Priority
+		if t.FileStats[i].Wanted {
+			sizeWhenDone += uint64(file.Length)
+			have += uint64(file.BytesCompleted)
+		}
+	}
+	t.SizeWhenDone = sizeWhenDone
+	t.HaveValid = have
+	t.LeftUntilDone = sizeWhenDone - have
+	t.PercentDone = 1
+	if sizeWhenDone > 0 {
+		t.PercentDone = float32(have) / float32(sizeWhenDone)
+	}
+}
+
+// selectTorrents resolves the ids argument of a request. A missing ids
+// selects every torrent.
+func (s *Server) selectTorrents(ids json.RawMessage) ([]*torrentState, error) {
+	if len(ids) == 0 || string(ids) == "null" {
+		return s.torrents, nil
+	}
+	var selector string
+	if json.Unmarshal(ids, &selector) == nil {
+		if selector != "recently-active" {
+			return nil, methodError("invalid ids")
+		}
+		var selected []*torrentState
+		for _, t := range s.torrents {
+			if s.now().Sub(t.changed) < recentlyActive {
+				selected = append(selected, t)
+			}
+		}
+		return selected, nil
+	}
+	var list []interface{}
+	if err := json.Unmarshal(ids, &list); err != nil {
+		return nil, methodError("invalid ids")
+	}
+	var selected []*torrentState
+	for _, t := range s.torrents {
+		for _, id := range list {
+			switch id := id.(type) {
+			case float64:
+				if int(id) == t.ID {
+					selected = append(selected, t)
+				}
+			case string:
+				if strings.EqualFold(id, t.HashString) {
+					selected = append(selected, t)
+				}
+			}
+		}
+	}
+	return selected, nil
+}
+
+type torrentAddRequestArgs struct {
+	Filename    string   `json:"filename"`
+	Metainfo    string   `json:"metainfo"`
+	DownloadDir string   `json:"download-dir"`
+	Paused      bool     `json:"paused"`
+	Labels      []string `json:"labels"`
+}
+
+type torrentAddResponseArgs struct {
+	TorrentAdded     *addedTorrent `json:"torrent-added,omitempty"`
+	TorrentDuplicate *addedTorrent `json:"torrent-duplicate,omitempty"`
+}
+
+type addedTorrent struct {
+	HashString string `json:"hashString"`
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+}
+
+func (s *Server) torrentAdd(arguments json.RawMessage) (interface{}, error) {
+	var req torrentAddRequestArgs
+	if err := unmarshalArguments(arguments, &req); err != nil {
+		return nil, err
+	}
+	var torrent transmission.Torrent
+	switch {
+	case req.Metainfo != "":
+		data, err := base64.StdEncoding.DecodeString(req.Metainfo)
+		if err != nil {
+			return nil, methodError("invalid or corrupt torrent file")
+		}
+		m, err := metainfo.Parse(data)
+		if err != nil || m.Validate() != nil {
+			return nil, methodError("invalid or corrupt torrent file")
+		}
+		torrent.HashString = m.HashString()
+		torrent.Name = m.Info.Name
+		torrent.MetadataPercentComplete = 1
+		if _, ok := s.metadata[torrent.HashString]; !ok {
+			s.metadata[torrent.HashString] = metadataTorrent(m)
+		}
+	case strings.HasPrefix(req.Filename, "magnet:"):
+		magnet, err := transmission.ParseMagnet(req.Filename)
+		if err != nil {
+			return nil, methodError("invalid or corrupt torrent file")
+		}
+		torrent.HashString = magnet.HashString()
+		torrent.Name = magnet.DisplayName
+		torrent.MagnetLink = req.Filename
+	default:
+		return nil, methodError("invalid or corrupt torrent file")
+	}
+	for _, t := range s.torrents {
+		if t.HashString == torrent.HashString {
+			return torrentAddResponseArgs{
+				TorrentDuplicate: &addedTorrent{HashString: t.HashString, ID: t.ID, Name: t.Name},
+			}, nil
+		}
+	}
+	if torrent.Name == "" {
+		torrent.Name = torrent.HashString
+	}
+	torrent.DownloadDir = req.DownloadDir
+	torrent.Labels = req.Labels
+	if torrent.MetadataPercentComplete == 1 {
+		s.applyMetadata(&torrent)
+	}
+	id := s.addTorrent(torrent)
+	state := s.torrents[len(s.torrents)-1]
+	paused := req.Paused
+	if value, ok := s.session["start-added-torrents"].(bool); ok && !value && !strings.Contains(string(arguments), `"paused"`) {
+		paused = true
+	}
+	if !paused {
+		s.start(state)
+	}
+	return torrentAddResponseArgs{
+		TorrentAdded: &addedTorrent{HashString: state.HashString, ID: id, Name: state.Name},
+	}, nil
+}
+
+// applyMetadata copies the registered metadata of a torrent into it.
+// metadataTorrent returns the metadata of a torrent file, in the form given
+// to RegisterMetadata.
+func metadataTorrent(m *metainfo.MetaInfo) transmission.Torrent {
+	var torrent transmission.Torrent
+	torrent.HashString = m.HashString()
+	torrent.Name = m.Info.Name
+	torrent.Comment = m.Comment
+	torrent.Creator = m.CreatedBy
+	torrent.IsPrivate = m.Info.Private
+	torrent.PieceCount = m.PieceCount()
+	torrent.PieceSize = int(m.Info.PieceLength)
+	files := m.Files()
+	singleFile := len(m.Info.Files) == 0 && len(files) == 1 && len(files[0].Path) == 1 && files[0].Path[0] == m.Info.Name
+	for _, f := range files {
+		name := strings.Join(f.Path, "/")
+		if !singleFile {
+			name = m.Info.Name + "/" + name
+		}
+		torrent.Files = append(torrent.Files, transmission.TorrentFile{Length: int(f.Length), Name: name})
+	}
+	return torrent
+}
+
+func (s *Server) applyMetadata(torrent *transmission.Torrent) {
+	torrent.MetadataPercentComplete = 1
+	metadata, ok := s.metadata[torrent.HashString]
+	if !ok {
+		return
+	}
+	torrent.Name = metadata.Name
+	torrent.Files = append([]transmission.TorrentFile{}, metadata.Files...)
+	torrent.Comment = metadata.Comment
+	torrent.Creator = metadata.Creator
+	torrent.IsPrivate = metadata.IsPrivate
+	torrent.PieceCount = metadata.PieceCount
+	torrent.PieceSize = metadata.PieceSize
+}
+
+type torrentGetRequestArgs struct {
+	IDs    json.RawMessage `json:"ids"`
+	Fields []string        `json:"fields"`
+	Format string          `json:"format"`
+}
+
+func (s *Server) torrentGet(arguments json.RawMessage) (interface{}, error) {
+	var req torrentGetRequestArgs
+	if err := unmarshalArguments(arguments, &req); err != nil {
+		return nil, err
+	}
+	if len(req.Fields) == 0 {
+		return nil, methodError("no fields specified")
+	}
+	selected, err := s.selectTorrents(req.IDs)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{})
+	if string(req.IDs) == `"recently-active"` {
+		removed := []int{}
+		for _, r := range s.removed {
+			if s.now().Sub(r.time) < recentlyActive {
+				removed = append(removed, r.id)
+			}
+		}
+		result["removed"] = removed
+	}
+	if req.Format == "table" {
+		rows := [][]interface{}{stringsToInterfaces(req.Fields)}
+		for _, t := range selected {
+			fields, err := torrentFields(t.Torrent)
+			if err != nil {
+				return nil, err
+			}
+			row := make([]interface{}, len(req.Fields))
+			for i, field := range req.Fields {
+				row[i] = fields[field]
+			}
+			rows = append(rows, row)
+		}
+		result["torrents"] = rows
+		return result, nil
+	}
+	torrents := make([]map[string]interface{}, 0, len(selected))
+	for _, t := range selected {
+		fields, err := torrentFields(t.Torrent)
+		if err != nil {
+			return nil, err
+		}
+		object := make(map[string]interface{}, len(req.Fields))
+		for _, field := range req.Fields {
+			if value, ok := fields[field]; ok {
+				object[field] = value
+			}
+		}
+		torrents = append(torrents, object)
+	}
+	result["torrents"] = torrents
+	return result, nil
+}
+
+// torrentFields returns the torrent as a map keyed by its RPC field names.
+func torrentFields(torrent transmission.Torrent) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(torrent)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, value := range values {
+		out[i] = value
+	}
+	return out
+}
+
+// torrentSetFields are the torrent-set arguments that map directly onto a
+// field of transmission.Torrent.
+var torrentSetFields = map[string]bool{
+	"bandwidthPriority":   true,
+	"downloadLimit":       true,
+	"downloadLimited":     true,
+	"group":               true,
+	"honorsSessionLimits": true,
+	"labels":              true,
+	"peer-limit":          true,
+	"seedIdleLimit":       true,
+	"seedIdleMode":        true,
+	"seedRatioLimit":      true,
+	"seedRatioMode":       true,
+	"trackerList":         true,
+	"uploadLimit":         true,
+	"uploadLimited":       true,
+}
+
+func (s *Server) torrentSet(arguments json.RawMessage) error {
+	var req map[string]json.RawMessage
+	if err := unmarshalArguments(arguments, &req); err != nil {
+		return err
+	}
+	selected, err := s.selectTorrents(req["ids"])
+	if err != nil {
+		return err
+	}
+	fileLists := make(map[string][]int)
+	for _, key := range []string{"files-wanted", "files-unwanted", "priority-high", "priority-low", "priority-normal"} {
+		if value, ok := req[key]; ok {
+			var indices []int
+			if err := json.Unmarshal(value, &indices); err != nil {
+				return methodError("invalid " + key)
+			}
+			fileLists[key] = indices
+		}
+	}
+	for _, t := range selected {
+		fields, err := torrentFields(t.Torrent)
+		if err != nil {
+			return err
+		}
+		for key, value := range req {
+			if torrentSetFields[key] {
+				fields[key] = value
+			}
+		}
+		encoded, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		var updated transmission.Torrent
+		if err := json.Unmarshal(encoded, &updated); err != nil {
+			return methodError("invalid arguments: " + err.Error())
+		}
+		t.Torrent = updated
+		for key, indices := range fileLists {
+			t.setFiles(key, indices)
+		}
+		t.updateSizes()
+		if value, ok := req["queuePosition"]; ok {
+			var position int
+			if err := json.Unmarshal(value, &position); err != nil {
+				return methodError("invalid queuePosition")
+			}
+			s.moveInQueue(t, position)
+		}
+		s.touch(t)
+	}
+	return nil
+}
+
+// setFiles applies a files-wanted, files-unwanted or priority list. An empty
+// list applies to every file, as in Transmission.
+func (t *torrentState) setFiles(key string, indices []int) {
+	if len(t.FileStats) == 0 {
+		return
+	}
+	if len(indices) == 0 {
+		indices = make([]int, len(t.FileStats))
+		for i := range indices {
+			indices[i] = i
+		}
+	}
+	for _, i := range indices {
+		if i < 0 || i >= len(t.FileStats) {
+			continue
+		}
+		switch key {
+		case "files-wanted":
+			t.FileStats[i].Wanted = true
+		case "files-unwanted":
+			t.FileStats[i].Wanted = false
+		case "priority-high":
+			t.FileStats[i].Priority = transmission.PriorityHigh
+		case "priority-low":
+			t.FileStats[i].Priority = transmission.PriorityLow
+		case "priority-normal":
+			t.FileStats[i].Priority = transmission.PriorityNormal
+		}
+	}
+}
+
+type torrentRemoveRequestArgs struct {
+	IDs             json.RawMessage `json:"ids"`
+	DeleteLocalData bool            `json:"delete-local-data"`
+}
+
+func (s *Server) torrentRemove(arguments json.RawMessage) error {
+	var req torrentRemoveRequestArgs
+	if err := unmarshalArguments(arguments, &req); err != nil {
+		return err
+	}
+	selected, err := s.selectTorrents(req.IDs)
+	if err != nil {
+		return err
+	}
+	remove := make(map[*torrentState]bool, len(selected))
+	for _, t := range selected {
+		remove[t] = true
+		s.removed = append(s.removed, removal{id: t.ID, time: s.now()})
+	}
+	kept := s.torrents[:0]
+	for _, t := range s.torrents {
+		if !remove[t] {
+			kept = append(kept, t)
+		}
+	}
+	s.torrents = kept
+	s.renumberQueue()
+	return nil
+}
+
+type torrentSetLocationRequestArgs struct {
+	IDs      json.RawMessage `json:"ids"`
+	Location string          `json:"location"`
+	Move     bool            `json:"move"`
+}
+
+func (s *Server) torrentSetLocation(arguments json.RawMessage) error {
+	var req torrentSetLocationRequestArgs
+	if err := unmarshalArguments(arguments, &req); err != nil {
+		return err
+	}
+	if req.Location == "" {
+		return methodError("no location")
+	}
+	selected, err := s.selectTorrents(req.IDs)
+	if err != nil {
+		return err
+	}
+	for _, t := range selected {
+		t.DownloadDir = req.Location
+		s.touch(t)
+	}
+	return nil
+}
+
+type torrentActionRequestArgs struct {
+	IDs json.RawMessage `json:"ids"`
+}
+
+func (s *Server) torrentAction(arguments json.RawMessage, action func(*torrentState)) error {
+	var req torrentActionRequestArgs
+	if err := unmarshalArguments(arguments, &req); err != nil {
+		return err
+	}
+	selected, err := s.selectTorrents(req.IDs)
+	if err != nil {
+		return err
+	}
+	for _, t := range selected {
+		action(t)
+		s.touch(t)
+	}
+	return nil
+}
+
+func (s *Server) start(t *torrentState) {
+	t.Error = transmission.TorrentErrorNone
+	t.ErrorString = ""
+	t.StartDate = uint64(s.now().Unix())
+	t.IsFinished = false
+	if t.MetadataPercentComplete >= 1 && t.LeftUntilDone == 0 {
+		t.Status = transmission.StatusSeed
+	} else {
+		t.Status = transmission.StatusDownload
+	}
+	s.touch(t)
+}
+
+func (s *Server) stopTorrent(t *torrentState) {
+	t.Status = transmission.StatusStopped
+	t.RateDownload = 0
+	t.RateUpload = 0
+	t.ETA = -1
+}
+
+func (s *Server) verify(t *torrentState) {
+	if t.Status != transmission.StatusCheckWait && t.Status != transmission.StatusCheck {
+		t.resumeStatus = t.Status
+	}
+	t.Status = transmission.StatusCheckWait
+	t.RecheckProgress = 0
+}
+
+func (s *Server) queueMove(method string, arguments json.RawMessage) error {
+	var req torrentActionRequestArgs
+	if err := unmarshalArguments(arguments, &req); err != nil {
+		return err
+	}
+	selected, err := s.selectTorrents(req.IDs)
+	if err != nil {
+		return err
+	}
+	for _, t := range selected {
+		switch method {
+		case "queue-move-top":
+			s.moveInQueue(t, 0)
+		case "queue-move-up":
+			s.moveInQueue(t, t.QueuePosition-1)
+		case "queue-move-down":
+			s.moveInQueue(t, t.QueuePosition+1)
+		case "queue-move-bottom":
+			s.moveInQueue(t, len(s.torrents)-1)
+		}
+		s.touch(t)
+	}
+	return nil
+}
+
+// moveInQueue moves a torrent to a queue position, shifting the others.
+func (s *Server) moveInQueue(t *torrentState, position int) {
+	if position < 0 {
+		position = 0
+	}
+	if position >= len(s.torrents) {
+		position = len(s.torrents) - 1
+	}
+	for i, other := range s.torrents {
+		if other == t {
+			s.torrents = append(s.torrents[:i], s.torrents[i+1:]...)
+			break
+		}
+	}
+	s.torrents = append(s.torrents[:position], append([]*torrentState{t}, s.torrents[position:]...)...)
+	s.renumberQueue()
+}
+
+func (s *Server) renumberQueue() {
+	for i, t := range s.torrents {
+		t.QueuePosition = i
+	}
+}
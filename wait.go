@@ -0,0 +1,203 @@
+package transmission
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+)
+
+const (
+	defaultPollInterval    = time.Second
+	defaultMaxPollInterval = 30 * time.Second
+)
+
+// waitFields are the fields polled by WaitFor, enough for the conditions in
+// this package.
+var waitFields = []string{
+	"id", "status", "error", "errorString", "eta", "isFinished", "percentDone", "leftUntilDone",
+	"metadataPercentComplete", "recheckProgress", "uploadRatio", "downloadDir",
+}
+
+type waitConfig struct {
+	interval    time.Duration
+	maxInterval time.Duration
+	fields      []string
+	progress    func(*Torrent)
+}
+
+type WaitOption func(*waitConfig)
+
+// PollIntervalOption sets the shortest time between polls, a second by default.
+func PollIntervalOption(interval time.Duration) WaitOption {
+	return func(c *waitConfig) {
+		c.interval = interval
+	}
+}
+
+// MaxPollIntervalOption sets the longest time between polls, 30 seconds by
+// default. The interval grows towards it while the torrent doesn't change.
+func MaxPollIntervalOption(interval time.Duration) WaitOption {
+	return func(c *waitConfig) {
+		c.maxInterval = interval
+	}
+}
+
+// WaitFieldsOption polls extra fields, for conditions that need more than
+// the defaults.
+func WaitFieldsOption(fields ...string) WaitOption {
+	return func(c *waitConfig) {
+		c.fields = append(c.fields, fields...)
+	}
+}
+
+// ProgressOption calls fn with every polled state of the torrent.
+func ProgressOption(fn func(*Torrent)) WaitOption {
+	return func(c *waitConfig) {
+		c.progress = fn
+	}
+}
+
+func newWaitConfig(opts []WaitOption) waitConfig {
+	config := waitConfig{
+		interval:    defaultPollInterval,
+		maxInterval: defaultMaxPollInterval,
+		fields:      append([]string{}, waitFields...),
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.maxInterval < config.interval {
+		config.maxInterval = config.interval
+	}
+	return config
+}
+
+// nextInterval polls again after half the torrent's ETA if it has one, and
+// otherwise backs off while the torrent isn't changing.
+func (c waitConfig) nextInterval(current time.Duration, torrent, previous *Torrent) time.Duration {
+	next := c.interval
+	switch {
+	case torrent.ETA > 0:
+		next = time.Duration(torrent.ETA) * time.Second / 2
+	case previous != nil && !progressed(torrent, previous):
+		next = current * 2
+	}
+	if next < c.interval {
+		return c.interval
+	}
+	if next > c.maxInterval {
+		return c.maxInterval
+	}
+	return next
+}
+
+func progressed(torrent, previous *Torrent) bool {
+	return torrent.Status != previous.Status ||
+		torrent.PercentDone != previous.PercentDone ||
+		torrent.RecheckProgress != previous.RecheckProgress ||
+		torrent.MetadataPercentComplete != previous.MetadataPercentComplete ||
+		torrent.UploadRatio != previous.UploadRatio ||
+		torrent.DownloadDir != previous.DownloadDir
+}
+
+// WaitFor polls a torrent until cond returns true, and returns its last
+// state. It fails if the torrent is removed or stops with a local error
+// before cond is met. Use a context with a deadline to limit the wait.
+func (t *Client) WaitFor(ctx context.Context, id TorrentID, cond func(*Torrent) bool, opts ...WaitOption) (*Torrent, error) {
+	config := newWaitConfig(opts)
+	interval := config.interval
+	var previous *Torrent
+	for {
+		var torrent *Torrent
+		err := t.IterateTorrents(ctx, func(tor Torrent) error {
+			torrent = &tor
+			return nil
+		}, TorrentIDsOption(id), TorrentFieldsOption(config.fields...))
+		if err != nil {
+			return previous, err
+		}
+		if torrent == nil {
+			return previous, fmt.Errorf("torrent %s was removed", id)
+		}
+		if config.progress != nil {
+			config.progress(torrent)
+		}
+		if cond(torrent) {
+			return torrent, nil
+		}
+		if torrent.Error == TorrentErrorLocal {
+			return torrent, fmt.Errorf("torrent %s failed: %s", id, torrent.ErrorString)
+		}
+		interval = config.nextInterval(interval, torrent, previous)
+		previous = torrent
+		if err := sleep(ctx, interval); err != nil {
+			return torrent, err
+		}
+	}
+}
+
+// sleep waits for d, returning early with the context's error if ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// MetadataReceived is true once the metadata of a magnet link has downloaded.
+func MetadataReceived(t *Torrent) bool {
+	return t.MetadataPercentComplete >= 1
+}
+
+// Completed is true once every wanted file has downloaded.
+func Completed(t *Torrent) bool {
+	return MetadataReceived(t) && t.LeftUntilDone == 0 && t.PercentDone >= 1
+}
+
+// Verified returns a condition that is true once the torrent was seen waiting
+// for or running a verify, and has left it since. Make a new condition for
+// every wait, and start waiting right after VerifyTorrents: a verify that
+// finishes before the first poll is never seen, and the wait only ends with
+// its context.
+func Verified() func(*Torrent) bool {
+	checked := false
+	return func(t *Torrent) bool {
+		if t.Status == StatusCheckWait || t.Status == StatusCheck {
+			checked = true
+			return false
+		}
+		return checked
+	}
+}
+
+// Seeding is true while the torrent is seeding.
+func Seeding(t *Torrent) bool {
+	return t.Status == StatusSeed
+}
+
+// Stopped is true while the torrent is stopped.
+func Stopped(t *Torrent) bool {
+	return t.Status == StatusStopped
+}
+
+// RatioReached returns a condition that is true once the torrent's upload
+// ratio reaches ratio.
+func RatioReached(ratio float64) func(*Torrent) bool {
+	return func(t *Torrent) bool {
+		return t.UploadRatio >= ratio
+	}
+}
+
+// MovedTo returns a condition that is true once the torrent's download
+// directory is dir.
+func MovedTo(dir string) func(*Torrent) bool {
+	dir = path.Clean(dir)
+	return func(t *Torrent) bool {
+		return path.Clean(t.DownloadDir) == dir
+	}
+}
@@ -0,0 +1,55 @@
+package transmission_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+)
+
+func TestVerified(t *testing.T) {
+	verified := transmission.Verified()
+	steps := []struct {
+		status int
+		want   bool
+	}{
+		// Before the verify is queued the torrent is still stopped
+		{transmission.StatusStopped, false},
+		{transmission.StatusCheckWait, false},
+		{transmission.StatusCheck, false},
+		{transmission.StatusStopped, true},
+	}
+	for i, step := range steps {
+		var torrent transmission.Torrent
+		torrent.Status = step.status
+		if got := verified(&torrent); got != step.want {
+			t.Errorf("step %d: Verified with status %d returned %t, want %t", i, step.status, got, step.want)
+		}
+	}
+}
+
+func TestWaitForVerify(t *testing.T) {
+	client, server := newTestClient(t)
+	var torrent transmission.Torrent
+	torrent.HashString = "0123456789abcdef0123456789abcdef01234567"
+	torrent.Status = transmission.StatusSeed
+	id := server.AddTorrent(torrent)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.VerifyTorrents(ctx, transmission.ID(id)); err != nil {
+		t.Fatalf("VerifyTorrents: %v", err)
+	}
+	polls := 0
+	progress := transmission.ProgressOption(func(*transmission.Torrent) {
+		polls++
+		server.Advance(time.Second)
+	})
+	got, err := client.WaitFor(ctx, transmission.ID(id), transmission.Verified(), transmission.PollIntervalOption(time.Millisecond), progress)
+	if err != nil {
+		t.Fatalf("WaitFor: %v", err)
+	}
+	if polls != 2 || got.Status != transmission.StatusSeed {
+		t.Errorf("verify ended after %d polls with status %d, want 2 polls and seeding", polls, got.Status)
+	}
+}
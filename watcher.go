@@ -0,0 +1,380 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+type EventType int
+
+const (
+	EventAdded EventType = iota
+	EventRemoved
+	EventMetadataReceived
+	EventStarted
+	EventStopped
+	EventCompleted
+	EventStatusChanged
+	EventErrorRaised
+	EventErrorCleared
+	EventRatioReached
+	EventLabelsChanged
+)
+
+var eventTypeNames = map[EventType]string{
+	EventAdded:            "added",
+	EventRemoved:          "removed",
+	EventMetadataReceived: "metadata-received",
+	EventStarted:          "started",
+	EventStopped:          "stopped",
+	EventCompleted:        "completed",
+	EventStatusChanged:    "status-changed",
+	EventErrorRaised:      "error-raised",
+	EventErrorCleared:     "error-cleared",
+	EventRatioReached:     "ratio-reached",
+	EventLabelsChanged:    "labels-changed",
+}
+
+func (e EventType) String() string {
+	if name, ok := eventTypeNames[e]; ok {
+		return name
+	}
+	return fmt.Sprintf("EventType(%d)", int(e))
+}
+
+// Event is a change of a torrent between two polls of a Watcher.
+type Event struct {
+	Type EventType
+	// Torrent is the current state, or the last known state if it was removed
+	Torrent Torrent
+	// Previous is the state at the previous poll, nil for EventAdded
+	Previous *Torrent
+	Time     time.Time
+}
+
+// Backpressure decides what a Watcher does when its event channel is full.
+type Backpressure int
+
+const (
+	// BackpressureBlock stops polling until the consumer catches up.
+	BackpressureBlock Backpressure = iota
+	// BackpressureDropOldest discards the oldest buffered event.
+	BackpressureDropOldest
+	// BackpressureDropNewest discards the new event.
+	BackpressureDropNewest
+)
+
+// watchFields are the fields a Watcher polls, enough to detect every event.
+var watchFields = []string{
+	"id", "hashString", "name", "status", "error", "errorString", "percentDone", "leftUntilDone",
+	"metadataPercentComplete", "uploadRatio", "seedRatioPercentDone", "labels", "downloadDir", "isFinished",
+}
+
+// ErrWatcherRan is returned by Run when the Watcher already ran.
+var ErrWatcherRan = errors.New("watcher already ran, its events channel is closed")
+
+// Watcher polls the torrent list and emits an Event for every change it sees.
+// A Watcher runs only once, as its events channel is closed when Run returns.
+type Watcher struct {
+	dropped       uint64
+	ran           uint32
+	client        TorrentReader
+	interval      time.Duration
+	fullSyncEvery int
+	ratio         float64
+	backpressure  Backpressure
+	emitExisting  bool
+	onError       func(error)
+	fields        []string
+	events        chan Event
+	torrents      map[int]Torrent
+}
+
+type WatcherOption func(*Watcher)
+
+// WatchIntervalOption sets the time between polls, 5 seconds by default.
+func WatchIntervalOption(interval time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.interval = interval
+	}
+}
+
+// WatchFullSyncOption only fetches the whole torrent list every n polls, and
+// the recently active torrents in between. By default every poll fetches the
+// whole list.
+func WatchFullSyncOption(n int) WatcherOption {
+	return func(w *Watcher) {
+		w.fullSyncEvery = n
+	}
+}
+
+// WatchRatioOption emits EventRatioReached when a torrent's upload ratio
+// reaches ratio. By default it is emitted when a torrent reaches its own seed
+// ratio limit.
+func WatchRatioOption(ratio float64) WatcherOption {
+	return func(w *Watcher) {
+		w.ratio = ratio
+	}
+}
+
+// WatchBufferOption sets the size of the event channel, 64 by default.
+func WatchBufferOption(size int) WatcherOption {
+	return func(w *Watcher) {
+		w.events = make(chan Event, size)
+	}
+}
+
+// WatchBackpressureOption sets what happens when the event channel is full.
+func WatchBackpressureOption(backpressure Backpressure) WatcherOption {
+	return func(w *Watcher) {
+		w.backpressure = backpressure
+	}
+}
+
+// WatchExistingOption emits EventAdded for the torrents present at the first
+// poll, which are otherwise taken as the starting point silently.
+func WatchExistingOption() WatcherOption {
+	return func(w *Watcher) {
+		w.emitExisting = true
+	}
+}
+
+// WatchErrorOption is called with the errors of failed polls. The watcher
+// keeps polling after an error.
+func WatchErrorOption(onError func(error)) WatcherOption {
+	return func(w *Watcher) {
+		w.onError = onError
+	}
+}
+
+// WatchFieldsOption polls extra fields, so they are set in the torrents of
+// the events.
+func WatchFieldsOption(fields ...string) WatcherOption {
+	return func(w *Watcher) {
+		w.fields = append(w.fields, fields...)
+	}
+}
+
+func NewWatcher(client TorrentReader, opts ...WatcherOption) *Watcher {
+	w := &Watcher{
+		client:   client,
+		interval: 5 * time.Second,
+		fields:   append([]string{}, watchFields...),
+		events:   make(chan Event, 64),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Events returns the channel events are emitted on. It is closed when Run
+// returns.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Dropped returns the number of events discarded because the channel was full.
+func (w *Watcher) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Run polls until ctx is done, and returns the context's error. It can only
+// be called once, later calls return ErrWatcherRan; make a new Watcher to
+// watch again.
+func (w *Watcher) Run(ctx context.Context) error {
+	if !atomic.CompareAndSwapUint32(&w.ran, 0, 1) {
+		return ErrWatcherRan
+	}
+	defer close(w.events)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for poll := 0; ; poll++ {
+		if err := w.poll(ctx, poll); err != nil && ctx.Err() == nil && w.onError != nil {
+			w.onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) poll(ctx context.Context, poll int) error {
+	now := time.Now()
+	if w.torrents == nil || w.fullSyncEvery <= 0 || poll%w.fullSyncEvery == 0 {
+		return w.fullSync(ctx, now)
+	}
+	torrents, removed, err := w.client.GetRecentlyActiveTorrents(ctx, TorrentFieldsOption(w.fields...))
+	if err != nil {
+		return err
+	}
+	for _, torrent := range torrents {
+		if !w.update(ctx, torrent, now) {
+			return ctx.Err()
+		}
+	}
+	for _, id := range removed {
+		if !w.remove(ctx, id, now) {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (w *Watcher) fullSync(ctx context.Context, now time.Time) error {
+	first := w.torrents == nil
+	current := make(map[int]Torrent, len(w.torrents))
+	var listed []Torrent
+	err := w.client.IterateTorrents(ctx, func(torrent Torrent) error {
+		current[torrent.ID] = torrent
+		if first {
+			listed = append(listed, torrent)
+			return nil
+		}
+		if !w.update(ctx, torrent, now) {
+			return ctx.Err()
+		}
+		return nil
+	}, TorrentFieldsOption(w.fields...))
+	if err != nil {
+		return err
+	}
+	if first {
+		// The torrents of the first poll are only emitted once it succeeded,
+		// or a failed first poll would emit them again at the next one
+		if w.emitExisting {
+			for _, torrent := range listed {
+				if !w.update(ctx, torrent, now) {
+					return ctx.Err()
+				}
+			}
+		}
+		w.torrents = current
+		return nil
+	}
+	for id := range w.torrents {
+		if _, ok := current[id]; !ok && !w.remove(ctx, id, now) {
+			return ctx.Err()
+		}
+	}
+	w.torrents = current
+	return nil
+}
+
+// update records the new state of a torrent and emits the events for it. It
+// returns false if ctx was done before the events could be emitted.
+func (w *Watcher) update(ctx context.Context, torrent Torrent, now time.Time) bool {
+	if w.torrents == nil {
+		w.torrents = make(map[int]Torrent)
+	}
+	previous, known := w.torrents[torrent.ID]
+	w.torrents[torrent.ID] = torrent
+	if !known {
+		return w.emit(ctx, Event{Type: EventAdded, Torrent: torrent, Time: now})
+	}
+	for _, eventType := range w.changes(&previous, &torrent) {
+		if !w.emit(ctx, Event{Type: eventType, Torrent: torrent, Previous: &previous, Time: now}) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *Watcher) remove(ctx context.Context, id int, now time.Time) bool {
+	previous, known := w.torrents[id]
+	if !known {
+		return true
+	}
+	delete(w.torrents, id)
+	return w.emit(ctx, Event{Type: EventRemoved, Torrent: previous, Previous: &previous, Time: now})
+}
+
+// changes lists the events between two states of a torrent.
+func (w *Watcher) changes(previous, torrent *Torrent) []EventType {
+	var events []EventType
+	if !MetadataReceived(previous) && MetadataReceived(torrent) {
+		events = append(events, EventMetadataReceived)
+	}
+	if previous.Status != torrent.Status {
+		if previous.Status == StatusStopped {
+			events = append(events, EventStarted)
+		}
+		if torrent.Status == StatusStopped {
+			events = append(events, EventStopped)
+		}
+		events = append(events, EventStatusChanged)
+	}
+	if !Completed(previous) && Completed(torrent) {
+		events = append(events, EventCompleted)
+	}
+	if torrent.Error != TorrentErrorNone && (previous.Error != torrent.Error || previous.ErrorString != torrent.ErrorString) {
+		events = append(events, EventErrorRaised)
+	}
+	if previous.Error != TorrentErrorNone && torrent.Error == TorrentErrorNone {
+		events = append(events, EventErrorCleared)
+	}
+	if !w.ratioReached(previous) && w.ratioReached(torrent) {
+		events = append(events, EventRatioReached)
+	}
+	if !equalStrings(previous.Labels, torrent.Labels) {
+		events = append(events, EventLabelsChanged)
+	}
+	return events
+}
+
+func (w *Watcher) ratioReached(torrent *Torrent) bool {
+	if w.ratio > 0 {
+		return torrent.UploadRatio >= w.ratio
+	}
+	return torrent.SeedRatioPercentDone >= 1
+}
+
+// emit sends an event, applying the backpressure policy if the channel is
+// full. It returns false if ctx was done first.
+func (w *Watcher) emit(ctx context.Context, event Event) bool {
+	switch w.backpressure {
+	case BackpressureDropNewest:
+		select {
+		case w.events <- event:
+		default:
+			atomic.AddUint64(&w.dropped, 1)
+		}
+		return true
+	case BackpressureDropOldest:
+		for {
+			select {
+			case w.events <- event:
+				return true
+			default:
+			}
+			select {
+			case <-w.events:
+				atomic.AddUint64(&w.dropped, 1)
+			default:
+			}
+		}
+	}
+	select {
+	case w.events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
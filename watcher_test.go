@@ -0,0 +1,230 @@
+package transmission_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bobcob7/transmission-rpc"
+	"github.com/bobcob7/transmission-rpc/transmissionmock"
+)
+
+func TestWatcherRunsOnce(t *testing.T) {
+	client, _ := newTestClient(t)
+	watcher := transmission.NewWatcher(client)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := watcher.Run(ctx); err != context.Canceled {
+		t.Fatalf("Run returned %v, want context.Canceled", err)
+	}
+	if _, ok := <-watcher.Events(); ok {
+		t.Error("the events channel is open after Run returned")
+	}
+	if err := watcher.Run(context.Background()); err != transmission.ErrWatcherRan {
+		t.Errorf("second Run returned %v, want ErrWatcherRan", err)
+	}
+}
+
+// watchPoll is the answer of one poll of a scripted reader: the torrents it
+// lists, and the error it fails with after listing them.
+type watchPoll struct {
+	torrents []transmission.Torrent
+	err      error
+}
+
+// scriptedReader answers the polls of a Watcher from the script, and repeats
+// the last poll once it runs out. Each poll sends its number on the returned
+// channel before answering.
+func scriptedReader(script []watchPoll) (*transmissionmock.APIMock, <-chan int) {
+	polls := make(chan int, 100)
+	var mu sync.Mutex
+	count := 0
+	reader := &transmissionmock.APIMock{
+		IterateTorrentsFunc: func(ctx context.Context, fn func(transmission.Torrent) error, opts ...transmission.TorrentsOption) error {
+			mu.Lock()
+			poll := script[len(script)-1]
+			if count < len(script) {
+				poll = script[count]
+			}
+			count++
+			select {
+			case polls <- count:
+			default:
+			}
+			mu.Unlock()
+			for _, torrent := range poll.torrents {
+				if err := fn(torrent); err != nil {
+					return err
+				}
+			}
+			return poll.err
+		},
+	}
+	return reader, polls
+}
+
+// watchScript runs a Watcher until it has polled every step of the script,
+// and returns the events it emitted as id:type.
+func watchScript(t *testing.T, script []watchPoll, opts ...transmission.WatcherOption) ([]string, *transmission.Watcher) {
+	t.Helper()
+	reader, polls := scriptedReader(script)
+	opts = append([]transmission.WatcherOption{transmission.WatchIntervalOption(time.Millisecond)}, opts...)
+	watcher := transmission.NewWatcher(reader, opts...)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- watcher.Run(ctx)
+	}()
+	// The poll after the script only starts once the events of the last step
+	// were emitted
+	timeout := time.After(5 * time.Second)
+	for poll := 0; poll <= len(script); {
+		select {
+		case poll = <-polls:
+		case <-timeout:
+			t.Fatal("the watcher didn't finish the script")
+		}
+	}
+	cancel()
+	<-done
+	var events []string
+	for event := range watcher.Events() {
+		events = append(events, fmt.Sprintf("%d:%s", event.Torrent.ID, event.Type))
+	}
+	return events, watcher
+}
+
+func watchTorrent(id int, mutate func(*transmission.Torrent)) transmission.Torrent {
+	var torrent transmission.Torrent
+	torrent.ID = id
+	torrent.MetadataPercentComplete = 1
+	torrent.Status = transmission.StatusDownload
+	if mutate != nil {
+		mutate(&torrent)
+	}
+	return torrent
+}
+
+func TestWatcherChanges(t *testing.T) {
+	script := []watchPoll{
+		{torrents: []transmission.Torrent{
+			watchTorrent(1, func(t *transmission.Torrent) {
+				t.PercentDone = 0.5
+				t.LeftUntilDone = 100
+			}),
+			watchTorrent(2, nil),
+		}},
+		{torrents: []transmission.Torrent{
+			watchTorrent(1, func(t *transmission.Torrent) {
+				t.Status = transmission.StatusSeed
+				t.PercentDone = 1
+			}),
+			watchTorrent(3, func(t *transmission.Torrent) { t.MetadataPercentComplete = 0 }),
+		}},
+		{torrents: []transmission.Torrent{
+			watchTorrent(1, func(t *transmission.Torrent) {
+				t.Status = transmission.StatusStopped
+				t.PercentDone = 1
+				t.Error = transmission.TorrentErrorTrackerError
+				t.ErrorString = "unregistered torrent"
+				t.SeedRatioPercentDone = 1
+				t.Labels = []string{"tv"}
+			}),
+			watchTorrent(3, nil),
+		}},
+		{torrents: []transmission.Torrent{
+			watchTorrent(1, func(t *transmission.Torrent) {
+				t.PercentDone = 1
+				t.SeedRatioPercentDone = 1
+				t.Labels = []string{"tv"}
+			}),
+			watchTorrent(3, nil),
+		}},
+	}
+	events, _ := watchScript(t, script)
+	want := []string{
+		// Torrent 2 was removed, and 3 added without metadata
+		"1:status-changed", "1:completed", "3:added", "2:removed",
+		"1:stopped", "1:status-changed", "1:error-raised", "1:ratio-reached", "1:labels-changed", "3:metadata-received",
+		"1:started", "1:status-changed", "1:error-cleared",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got events\n%v\nwant\n%v", events, want)
+	}
+}
+
+func TestWatcherEmitExisting(t *testing.T) {
+	torrents := []transmission.Torrent{watchTorrent(1, nil), watchTorrent(2, nil)}
+	script := []watchPoll{
+		// The first poll fails after listing a torrent, which mustn't be
+		// emitted again at the next poll
+		{torrents: torrents[:1], err: errors.New("connection reset")},
+		{torrents: torrents},
+	}
+	var errs []error
+	events, _ := watchScript(t, script, transmission.WatchExistingOption(), transmission.WatchErrorOption(func(err error) {
+		errs = append(errs, err)
+	}))
+	if want := []string{"1:added", "2:added"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("got events %v, want %v", events, want)
+	}
+	if len(errs) != 1 {
+		t.Errorf("got errors %v, want the failed first poll", errs)
+	}
+
+	events, _ = watchScript(t, script)
+	if len(events) != 0 {
+		t.Errorf("got events %v without WatchExistingOption, want none", events)
+	}
+}
+
+func TestWatcherBackpressure(t *testing.T) {
+	var labelled []transmission.Torrent
+	for id := 1; id <= 4; id++ {
+		labelled = append(labelled, watchTorrent(id, func(t *transmission.Torrent) { t.Labels = []string{"new"} }))
+	}
+	script := []watchPoll{
+		{torrents: []transmission.Torrent{watchTorrent(1, nil), watchTorrent(2, nil), watchTorrent(3, nil), watchTorrent(4, nil)}},
+		{torrents: labelled},
+	}
+	tests := []struct {
+		backpressure transmission.Backpressure
+		want         []string
+		dropped      uint64
+	}{
+		{transmission.BackpressureDropNewest, []string{"1:labels-changed", "2:labels-changed"}, 2},
+		{transmission.BackpressureDropOldest, []string{"3:labels-changed", "4:labels-changed"}, 2},
+	}
+	for _, tt := range tests {
+		events, watcher := watchScript(t, script, transmission.WatchBufferOption(2), transmission.WatchBackpressureOption(tt.backpressure))
+		if !reflect.DeepEqual(events, tt.want) || watcher.Dropped() != tt.dropped {
+			t.Errorf("backpressure %d gave events %v, %d dropped, want %v, %d dropped", tt.backpressure, events, watcher.Dropped(), tt.want, tt.dropped)
+		}
+	}
+
+	// Blocking waits for the consumer, so no event is lost
+	reader, _ := scriptedReader(script)
+	watcher := transmission.NewWatcher(reader, transmission.WatchIntervalOption(time.Millisecond), transmission.WatchBufferOption(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+	for id := 1; id <= 4; id++ {
+		select {
+		case event := <-watcher.Events():
+			if event.Torrent.ID != id || event.Type != transmission.EventLabelsChanged {
+				t.Errorf("got event %d:%s, want %d:labels-changed", event.Torrent.ID, event.Type, id)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("blocked watcher didn't emit its events")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if watcher.Dropped() != 0 {
+		t.Errorf("blocking watcher dropped %d events", watcher.Dropped())
+	}
+}